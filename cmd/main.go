@@ -3,19 +3,26 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 	"user-admin/internal/config"
 	"user-admin/internal/delivery/v1/middleware"
 	"user-admin/internal/delivery/v1/routers"
+	"user-admin/internal/domain"
 	repository "user-admin/internal/repository/postgres"
 	"user-admin/internal/service"
 	"user-admin/pkg/database"
+	"user-admin/pkg/jwtkeys"
 	utils "user-admin/pkg/lib/utils"
 	"user-admin/pkg/logger"
+	"user-admin/pkg/mailer"
+	"user-admin/pkg/oidc"
+	"user-admin/pkg/password"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -46,21 +53,96 @@ func main() {
 	}
 	defer db.Close()
 
+	keySet, err := jwtkeys.NewKeySet(cfg.JWT)
+	if err != nil {
+		slog.Error("Failed to load JWT keys:", utils.Err(err))
+		os.Exit(1)
+	}
+
+	passwordPolicy, err := password.NewPolicy(cfg.PasswordPolicy)
+	if err != nil {
+		slog.Error("Failed to load password policy:", utils.Err(err))
+		os.Exit(1)
+	}
+
+	passwordHasher, err := password.NewHasher(cfg.PasswordHashing)
+	if err != nil {
+		slog.Error("Failed to set up password hashing:", utils.Err(err))
+		os.Exit(1)
+	}
+
+	mailSender, err := mailer.NewMailer(cfg.Mail)
+	if err != nil {
+		slog.Error("Failed to set up mailer:", utils.Err(err))
+		os.Exit(1)
+	}
+
 	mainRouter := chi.NewRouter()
 
-	authMiddlewareForAdmin := middleware.AuthMiddleware(cfg, []string{"admin"})
-	authMiddlewareForSuperAdmin := middleware.AuthMiddleware(cfg, []string{"super_admin"})
+	adminAuthRepository := repository.NewPostgresAdminAuthRepository(db.GetDB(), cfg.JWT, keySet, passwordHasher)
+
+	roleRepository := repository.NewPostgresRoleRepository(db.GetDB())
+
+	apiKeyRepository := repository.NewPostgresAPIKeyRepository(db.GetDB())
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, roleRepository)
+
+	roleService := service.NewRoleService(roleRepository)
+	// Every route declares the permission it requires, roles grant permissions to admins
+	authorizer := middleware.NewAuthorizer(roleService)
+
+	securityEventRepository := repository.NewPostgresSecurityEventRepository(db.GetDB())
+	adminRepository := repository.NewPostgresAdminRepository(db.GetDB(), passwordHasher)
+
+	// Access policies restrict where from and when admins may sign in and use their tokens
+	accessPolicyLocation, err := time.LoadLocation(cfg.AccessPolicyTimezone)
+	if err != nil {
+		slog.Error("Failed to load access policy timezone:", utils.Err(err))
+		os.Exit(1)
+	}
+	accessPolicyRepository := repository.NewPostgresAccessPolicyRepository(db.GetDB())
+	accessPolicyService := service.NewAccessPolicyService(accessPolicyRepository, adminRepository, roleRepository, securityEventRepository, accessPolicyLocation)
+
+	// Authenticated admins are tracked, so that dormant accounts stand out,
+	// and whatever an admin does while impersonating someone is audited
+	trackActivity := middleware.TrackActivity(adminAuthRepository)
+	auditImpersonation := middleware.AuditImpersonation(securityEventRepository)
+	authMiddlewareForAdmin := chi.Chain(middleware.AuthMiddleware(keySet, adminAuthRepository, nil, accessPolicyService), trackActivity, auditImpersonation).Handler
+	// The user API is also open to services holding an API key
+	authMiddlewareForUserAPI := chi.Chain(middleware.AuthMiddleware(keySet, adminAuthRepository, apiKeyService, accessPolicyService), trackActivity, auditImpersonation).Handler
+	authMiddlewareForMFAEnrollment := middleware.PurposeMiddleware(keySet, adminAuthRepository, accessPolicyService, domain.MFAEnrollmentTokenPurpose)
+	authMiddlewareForPasswordChange := middleware.PurposeMiddleware(keySet, adminAuthRepository, accessPolicyService, domain.PasswordChangeTokenPurpose)
+
+	// Public keys for services that verify our access tokens
+	routers.SetupJWKSRoutes(mainRouter, keySet)
 
 	// Admin routes
 	adminRouter := chi.NewRouter()
-	adminRouter.Use(authMiddlewareForSuperAdmin) // Apply auth middleware to admin routes
+	adminRouter.Use(authMiddlewareForAdmin) // Apply auth middleware to admin routes
 	mainRouter.Route("/api/admin", func(r chi.Router) {
 		r.Mount("/", adminRouter)
 	})
 
-	adminRepository := repository.NewPostgresAdminRepository(db.GetDB())
-	adminService := service.NewAdminService(adminRepository)
-	routers.SetupAdminRoutes(adminRouter, adminService)
+	loginAttemptRepository := repository.NewPostgresLoginAttemptRepository(db.GetDB())
+	loginEventRepository := repository.NewPostgresLoginEventRepository(db.GetDB())
+	passwordHistoryRepository := repository.NewPostgresPasswordHistoryRepository(db.GetDB())
+	passwordValidator := service.NewPasswordValidator(passwordPolicy, passwordHasher, passwordHistoryRepository)
+
+	adminService := service.NewAdminService(adminRepository, loginAttemptRepository, securityEventRepository, loginEventRepository, roleRepository, passwordValidator)
+	routers.SetupAdminRoutes(adminRouter, adminService, authorizer)
+	routers.SetupAPIKeyRoutes(adminRouter, apiKeyService, authorizer)
+	routers.SetupAccessPolicyRoutes(adminRouter, accessPolicyService, authorizer)
+
+	accessReviewRepository := repository.NewPostgresAccessReviewRepository(db.GetDB())
+	accessReviewService := service.NewAccessReviewService(accessReviewRepository, adminRepository, roleRepository, securityEventRepository, cfg.AccessReview)
+	routers.SetupAccessReviewRoutes(adminRouter, accessReviewService, authorizer)
+
+	// Role routes
+	roleRouter := chi.NewRouter()
+	roleRouter.Use(authMiddlewareForAdmin)
+	mainRouter.Route("/api/roles", func(r chi.Router) {
+		r.Mount("/", roleRouter)
+	})
+	routers.SetupRoleRoutes(roleRouter, roleService, authorizer)
 
 	// Authentication routes
 	authRouter := chi.NewRouter()
@@ -68,20 +150,40 @@ func main() {
 		r.Mount("/", authRouter)
 	})
 
-	adminAuthRepository := repository.NewPostgresAdminAuthRepository(db.GetDB(), cfg.JWT)
-	adminAuthService := service.NewAdminAuthService(adminAuthRepository)
-	routers.SetupAuthRoutes(authRouter, adminAuthService)
+	mfaRepository := repository.NewPostgresMFARepository(db.GetDB())
+	passwordResetRepository := repository.NewPostgresPasswordResetRepository(db.GetDB())
+	adminAuthService := service.NewAdminAuthService(adminAuthRepository, securityEventRepository, mfaRepository, loginAttemptRepository, loginEventRepository, roleRepository, passwordValidator, passwordHasher, passwordResetRepository, accessPolicyService, mailSender, cfg)
+	routers.SetupAuthRoutes(authRouter, adminAuthService, authMiddlewareForAdmin, authMiddlewareForMFAEnrollment, authMiddlewareForPasswordChange, authorizer, cfg.SessionCookies)
+
+	profileService := service.NewProfileService(adminRepository, roleRepository, adminAuthRepository)
+	routers.SetupProfileRoutes(authRouter, profileService, authMiddlewareForAdmin)
+
+	invitationRepository := repository.NewPostgresInvitationRepository(db.GetDB(), passwordHasher)
+	invitationService := service.NewInvitationService(invitationRepository, roleRepository, securityEventRepository, passwordValidator, mailSender, cfg.Invitation)
+	routers.SetupInvitationRoutes(adminRouter, authRouter, invitationService, authorizer)
+
+	// Single sign-on through the corporate identity provider
+	if cfg.OIDC.Enabled {
+		oidcRepository := repository.NewPostgresOIDCRepository(db.GetDB())
+		oidcService := service.NewOIDCService(oidc.NewClient(cfg.OIDC), oidcRepository, adminRepository, adminAuthService, cfg.OIDC)
+		routers.SetupOIDCRoutes(authRouter, oidcService)
+	}
 
 	// User routes
 	userRouter := chi.NewRouter()
-	userRouter.Use(authMiddlewareForAdmin)
+	userRouter.Use(authMiddlewareForUserAPI)
 	mainRouter.Route("/api/user", func(r chi.Router) {
 		r.Mount("/", userRouter)
 	})
 
 	userRepository := repository.NewPostgresUserRepository(db.GetDB())
-	userService := service.NewUserService(userRepository)
-	routers.SetupUserRoutes(userRouter, userService) // Set up user routes
+	userService := service.NewUserService(userRepository, securityEventRepository, cfg.UserRetention)
+	routers.SetupUserRoutes(userRouter, userService, authorizer) // Set up user routes
+
+	// Background jobs run until the server shuts down
+	jobs, stopJobs := context.WithCancel(context.Background())
+	go accessReviewService.RunDormancyJob(jobs)
+	go userService.RunPurgeJob(jobs)
 
 	// Handling graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -90,6 +192,7 @@ func main() {
 	go func() {
 		<-stop
 		log.Info("Shutting down the server gracefully...")
+		stopJobs()
 
 		if err := db.Close(); err != nil {
 			slog.Error("Error closing database:", utils.Err(err))
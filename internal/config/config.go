@@ -13,6 +13,18 @@ type Config struct {
 	Database   `yaml:"database"`
 	HTTPServer `yaml:"http_server"`
 	JWT
+	MFA             `yaml:"mfa"`
+	Lockout         `yaml:"lockout"`
+	PasswordPolicy  `yaml:"password_policy"`
+	PasswordHashing `yaml:"password_hashing"`
+	PasswordReset   `yaml:"password_reset"`
+	Mail            `yaml:"mail"`
+	OIDC            `yaml:"oidc"`
+	Invitation      `yaml:"invitation"`
+	SessionCookies  `yaml:"session_cookies"`
+	AccessPolicy    `yaml:"access_policy"`
+	AccessReview    `yaml:"access_review"`
+	UserRetention   `yaml:"user_retention"`
 }
 
 type Database struct {
@@ -31,8 +43,140 @@ type HTTPServer struct {
 }
 
 type JWT struct {
-	AccessSecretKey  string `yaml:"access_secret_key"`
-	RefreshSecretKey string `yaml:"refresh_secret_key"`
+	AccessSecretKey  string   `yaml:"access_secret_key"`
+	RefreshSecretKey string   `yaml:"refresh_secret_key"`
+	SigningKeyID     string   `yaml:"signing_key_id"`
+	Keys             []JWTKey `yaml:"keys"`
+}
+
+// JWTKey is an asymmetric key for access tokens. Keys that are being rotated
+// out only need their public key to keep verifying the tokens they signed.
+type JWTKey struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"` // RS256 or EdDSA
+	PrivateKeyFile string `yaml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file"`
+}
+
+type MFA struct {
+	Issuer string `yaml:"issuer" env-default:"Admin Panel"`
+}
+
+// Lockout configures the brute-force protection of the login endpoints. It cannot be turned off,
+// cleanenv applies the defaults to zero values.
+type Lockout struct {
+	MaxAttempts   int           `yaml:"max_attempts" env-default:"5"`
+	IPMaxAttempts int           `yaml:"ip_max_attempts" env-default:"20"`
+	Duration      time.Duration `yaml:"duration" env-default:"15m"`
+	Window        time.Duration `yaml:"window" env-default:"15m"`
+	BackoffBase   time.Duration `yaml:"backoff_base" env-default:"1s"`
+	BackoffMax    time.Duration `yaml:"backoff_max" env-default:"1m"`
+}
+
+// PasswordPolicy configures the rules for admin passwords. MaxAge of zero disables expiry.
+type PasswordPolicy struct {
+	MinLength     int           `yaml:"min_length" env-default:"12"`
+	RequireUpper  bool          `yaml:"require_upper"`
+	RequireLower  bool          `yaml:"require_lower"`
+	RequireDigit  bool          `yaml:"require_digit"`
+	RequireSymbol bool          `yaml:"require_symbol"`
+	BlocklistFile string        `yaml:"blocklist_file"`
+	HistorySize   int           `yaml:"history_size" env-default:"5"`
+	MaxAge        time.Duration `yaml:"max_age"`
+}
+
+// PasswordHashing configures how new admin passwords are hashed. Hashes made with other
+// algorithms or parameters keep verifying and are upgraded on the admin's next login.
+type PasswordHashing struct {
+	HashAlgorithm     string `yaml:"hash_algorithm" env-default:"argon2id"` // argon2id or bcrypt
+	Argon2Memory      uint32 `yaml:"argon2_memory" env-default:"65536"`     // KiB
+	Argon2Iterations  uint32 `yaml:"argon2_iterations" env-default:"3"`
+	Argon2Parallelism uint8  `yaml:"argon2_parallelism" env-default:"2"`
+	Argon2SaltLength  uint32 `yaml:"argon2_salt_length" env-default:"16"`
+	Argon2KeyLength   uint32 `yaml:"argon2_key_length" env-default:"32"`
+	BcryptCost        int    `yaml:"bcrypt_cost" env-default:"10"`
+}
+
+// PasswordReset configures the forgot password flow. ResetURL is the page of the admin panel
+// that accepts the token, it gets the token appended as the "token" query parameter.
+type PasswordReset struct {
+	ResetURL      string        `yaml:"reset_url"`
+	ResetTokenTTL time.Duration `yaml:"reset_token_ttl" env-default:"1h"`
+}
+
+// Invitation configures how new admins are invited. AcceptURL is the page of the admin panel
+// that accepts the invitation, it gets the token appended as the "token" query parameter.
+type Invitation struct {
+	AcceptURL     string        `yaml:"accept_url"`
+	InvitationTTL time.Duration `yaml:"invitation_ttl" env-default:"72h"`
+}
+
+// SessionCookies configures the browser session mode, where the admin panel keeps the tokens in
+// HttpOnly cookies instead of script-readable storage. Clients opt in per login, bearer tokens keep working.
+type SessionCookies struct {
+	CookieMode     bool   `yaml:"cookie_mode"`
+	CookieDomain   string `yaml:"cookie_domain"`
+	CookieSameSite string `yaml:"cookie_same_site" env-default:"strict"` // strict, lax or none
+	// CookieInsecure drops the Secure attribute so cookies work over plain HTTP in local development
+	CookieInsecure bool `yaml:"cookie_insecure"`
+}
+
+// AccessPolicy configures how the time windows of access policies are read
+type AccessPolicy struct {
+	AccessPolicyTimezone string `yaml:"timezone" env-default:"UTC"`
+}
+
+// AccessReview configures the automatic deactivation of dormant admins, which DisableDormancyCheck turns off.
+// cleanenv applies the defaults to zero values, so zero days or a zero interval mean the defaults.
+type AccessReview struct {
+	DisableDormancyCheck  bool          `yaml:"disable_dormancy_check"`
+	DormantAfterDays      int           `yaml:"dormant_after_days" env-default:"90"`
+	DormancyCheckInterval time.Duration `yaml:"dormancy_check_interval" env-default:"24h"`
+}
+
+// UserRetention configures how long deleted users stay in the trash before they are purged.
+// DisableUserPurge keeps them until they are purged by hand. cleanenv applies the defaults
+// to zero values, so a zero retention or interval means the default, negative ones are rejected.
+type UserRetention struct {
+	DisableUserPurge         bool          `yaml:"disable_purge"`
+	DeletedUserRetention     time.Duration `yaml:"deleted_user_retention" env-default:"720h"`
+	DeletedUserPurgeInterval time.Duration `yaml:"purge_interval" env-default:"24h"`
+}
+
+// Mail configures how outgoing mail is delivered. The file and log transports
+// only record the messages and are meant for local and test environments.
+type Mail struct {
+	Transport    string `yaml:"transport" env-default:"log"` // smtp, file or log
+	From         string `yaml:"from"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port" env-default:"587"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	FilePath     string `yaml:"file_path" env-default:"mail.log"`
+}
+
+// OIDC configures single sign-on through the corporate identity provider
+type OIDC struct {
+	Enabled      bool     `yaml:"enabled"`
+	IssuerURL    string   `yaml:"issuer_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes" env-default:"openid,profile,email"`
+	// UsernameClaim names the admin, existing admins with that username are linked on their first sign-in
+	UsernameClaim string `yaml:"username_claim" env-default:"preferred_username"`
+	GroupsClaim   string `yaml:"groups_claim" env-default:"groups"`
+	// RoleMappings are checked in order, the first group the admin is a member of decides the role
+	RoleMappings []OIDCRoleMapping `yaml:"role_mappings"`
+	// JITProvisioning creates admins that sign in for the first time
+	JITProvisioning bool `yaml:"jit_provisioning"`
+	// DisableLocalLogin turns off password login and password resets
+	DisableLocalLogin bool `yaml:"disable_local_login"`
+}
+
+type OIDCRoleMapping struct {
+	Group string `yaml:"group"`
+	Role  string `yaml:"role"`
 }
 
 func LoadConfig() *Config {
@@ -48,5 +192,9 @@ func LoadConfig() *Config {
 		log.Fatalf("Cannot read config: %v", utils.Err(err))
 	}
 
+	if cfg.DeletedUserRetention <= 0 || cfg.DeletedUserPurgeInterval <= 0 {
+		log.Fatalf("deleted_user_retention and purge_interval must be positive, use disable_purge to keep deleted users")
+	}
+
 	return &cfg
 }
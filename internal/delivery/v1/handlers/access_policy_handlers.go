@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AccessPolicyHandler struct {
+	AccessPolicyService *service.AccessPolicyService
+}
+
+func (h *AccessPolicyHandler) GetAccessPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.AccessPolicyService.GetAccessPolicies()
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, policies)
+}
+
+func (h *AccessPolicyHandler) CreateAccessPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.AccessPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	policy, err := h.AccessPolicyService.CreateAccessPolicy(principal, &request)
+	if err != nil {
+		respondWithAccessPolicyError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, policy)
+}
+
+func (h *AccessPolicyHandler) UpdateAccessPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	var request domain.AccessPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	request.ID = int32(id)
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	policy, err := h.AccessPolicyService.UpdateAccessPolicy(principal, &request)
+	if err != nil {
+		respondWithAccessPolicyError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, policy)
+}
+
+func (h *AccessPolicyHandler) DeleteAccessPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	if err := h.AccessPolicyService.DeleteAccessPolicy(int32(id)); err != nil {
+		respondWithAccessPolicyError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Access policy deleted successfully",
+	})
+}
+
+func respondWithAccessPolicyError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccessPolicyNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.AccessPolicyNotFound)
+	case domain.ErrInvalidAccessPolicy:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidAccessPolicy)
+	case domain.ErrRoleNotFound:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.RoleNotFound)
+	case domain.ErrRoleProtected:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleProtected)
+	case domain.ErrRoleOutranksCaller:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+	case domain.ErrAdminNotFound:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.AdminNotFound)
+	default:
+		slog.Error("Error managing access policy:", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AccessReviewHandler struct {
+	AccessReviewService *service.AccessReviewService
+}
+
+func (h *AccessReviewHandler) GetAccessReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.AccessReviewService.GetAccessReviews()
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, reviews)
+}
+
+func (h *AccessReviewHandler) GetAccessReviewHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	review, err := h.AccessReviewService.GetAccessReview(int32(id))
+	if err != nil {
+		respondWithAccessReviewError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, review)
+}
+
+func (h *AccessReviewHandler) CreateAccessReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateAccessReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	if strings.TrimSpace(request.Name) == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.AccessReviewNameRequired)
+		return
+	}
+
+	createdBy, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.APIKeyNotAccepted)
+		return
+	}
+
+	review, err := h.AccessReviewService.CreateAccessReview(request.Name, createdBy, clientInfo(r))
+	if err != nil {
+		respondWithAccessReviewError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, review)
+}
+
+func (h *AccessReviewHandler) ConfirmAdminHandler(w http.ResponseWriter, r *http.Request) {
+	reviewID, adminID, request, ok := decodeAccessReviewDecision(w, r)
+	if !ok {
+		return
+	}
+
+	decidedBy, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.APIKeyNotAccepted)
+		return
+	}
+
+	if err := h.AccessReviewService.ConfirmAdmin(reviewID, adminID, decidedBy, request.Comment); err != nil {
+		respondWithAccessReviewError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Admin access confirmed",
+	})
+}
+
+func (h *AccessReviewHandler) RevokeAdminHandler(w http.ResponseWriter, r *http.Request) {
+	reviewID, adminID, request, ok := decodeAccessReviewDecision(w, r)
+	if !ok {
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok || !principal.IsAdmin() {
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.APIKeyNotAccepted)
+		return
+	}
+
+	if err := h.AccessReviewService.RevokeAdmin(principal, reviewID, adminID, request.Comment, clientInfo(r)); err != nil {
+		respondWithAccessReviewError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Admin access revoked",
+	})
+}
+
+func (h *AccessReviewHandler) CloseAccessReviewHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	closedBy, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.APIKeyNotAccepted)
+		return
+	}
+
+	if err := h.AccessReviewService.CloseAccessReview(int32(id), closedBy, clientInfo(r)); err != nil {
+		respondWithAccessReviewError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Access review closed successfully",
+	})
+}
+
+// ExportAccessReviewHandler downloads the review as evidence, as CSV by default or as JSON with format=json
+func (h *AccessReviewHandler) ExportAccessReviewHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	if format != "csv" && format != "json" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidExportFormat)
+		return
+	}
+
+	review, err := h.AccessReviewService.GetAccessReview(int32(id))
+	if err != nil {
+		respondWithAccessReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="access-review-%d.%s"`, review.ID, format))
+
+	if format == "json" {
+		utils.RespondWithJSON(w, status.OK, review)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.WriteHeader(status.OK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"review_id", "review_name", "admin_id", "username", "role", "email", "disabled",
+		"last_login_at", "last_seen_at", "decision", "decided_by", "decided_at", "comment",
+	})
+
+	for _, item := range review.Items {
+		decision := item.Decision
+		if decision == "" {
+			decision = "pending"
+		}
+
+		decidedBy := ""
+		if item.DecidedBy != 0 {
+			decidedBy = strconv.Itoa(int(item.DecidedBy))
+		}
+
+		writer.Write([]string{
+			strconv.Itoa(int(review.ID)),
+			review.Name,
+			strconv.Itoa(int(item.AdminID)),
+			item.Username,
+			item.Role,
+			item.Email,
+			strconv.FormatBool(item.Disabled),
+			formatOptionalTime(item.LastLoginAt),
+			formatOptionalTime(item.LastSeenAt),
+			decision,
+			decidedBy,
+			formatOptionalTime(item.DecidedAt),
+			item.Comment,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		slog.Error("Error writing access review export:", utils.Err(err))
+	}
+}
+
+func decodeAccessReviewDecision(w http.ResponseWriter, r *http.Request) (int32, int32, domain.AccessReviewDecisionRequest, bool) {
+	var request domain.AccessReviewDecisionRequest
+
+	reviewID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return 0, 0, request, false
+	}
+
+	adminID, err := strconv.Atoi(chi.URLParam(r, "adminID"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return 0, 0, request, false
+	}
+
+	// The comment is optional, so is the body
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+			return 0, 0, request, false
+		}
+	}
+
+	request.Comment = strings.TrimSpace(request.Comment)
+
+	return int32(reviewID), int32(adminID), request, true
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func respondWithAccessReviewError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccessReviewNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.AccessReviewNotFound)
+	case domain.ErrAccessReviewClosed:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.AccessReviewClosed)
+	case domain.ErrAccessReviewItemNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.AccessReviewItemNotFound)
+	case domain.ErrAccessReviewItemDecided:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.AccessReviewItemDecided)
+	case domain.ErrRoleOutranksCaller:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+	case domain.ErrCannotDisableSelf:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.CannotDisableSelf)
+	default:
+		slog.Error("Error managing access review:", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
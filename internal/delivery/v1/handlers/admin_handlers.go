@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+	"user-admin/internal/delivery/v1/middleware"
 	"user-admin/internal/domain"
 	"user-admin/internal/service"
 	"user-admin/pkg/lib/errors"
@@ -22,26 +24,27 @@ type AdminHandler struct {
 }
 
 func (h *AdminHandler) GetAllAdminsHandler(w http.ResponseWriter, r *http.Request) {
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = 1
+	adminStatus := r.URL.Query().Get("status")
+	if !domain.IsAdminStatus(adminStatus) {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidAdminStatus)
+		return
 	}
 
-	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if err != nil || pageSize <= 0 {
-		pageSize = 8 // Default page size
+	sort, ok := parseSort(w, r, domain.AdminSortFields)
+	if !ok {
+		return
 	}
 
-	var previousPage int
-	if page > 1 {
-		previousPage = page - 1
-	} else {
-		previousPage = 1
+	page, ok := parsePageRequest(w, r, sort)
+	if !ok {
+		return
 	}
 
-	nextPage := page + 1
-
-	admins, err := h.AdminService.GetAllAdmins(page, pageSize)
+	admins, info, err := h.AdminService.GetAllAdmins(adminStatus, sort, page)
+	if err == domain.ErrInvalidCursor {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidCursor)
+		return
+	}
 	if err != nil {
 		slog.Error("Error getting admins: ", utils.Err(err))
 		http.Error(w, errors.InternalServerError, status.InternalServerError)
@@ -49,15 +52,11 @@ func (h *AdminHandler) GetAllAdminsHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	response := struct {
-		Admins      *domain.AdminsList `json:"admins"`
-		CurrentPage int                `json:"currentPage"`
-		PrevPage    int                `json:"previousPage"`
-		NextPage    int                `json:"nextPage"`
+		Admins *domain.AdminsList `json:"admins"`
+		pageMetadata
 	}{
-		Admins:      admins,
-		CurrentPage: page,
-		PrevPage:    previousPage,
-		NextPage:    nextPage,
+		Admins:       admins,
+		pageMetadata: newPageMetadata(w, r, page, info),
 	}
 
 	utils.RespondWithJSON(w, status.OK, response)
@@ -95,11 +94,29 @@ func (h *AdminHandler) CreateAdminHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	createdAdmin, err := h.AdminService.CreateAdmin(&admin)
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	createdAdmin, err := h.AdminService.CreateAdmin(principal, &admin)
 	if err != nil {
+		if respondWithPasswordError(w, err) {
+			return
+		}
+
 		switch err {
 		case domain.ErrAdminAlreadyExists:
 			utils.RespondWithErrorJSON(w, status.Conflict, "Admin with the same username already exists")
+		case domain.ErrAdminEmailExists:
+			utils.RespondWithErrorJSON(w, status.Conflict, errors.AdminEmailExists)
+		case domain.ErrInvalidEmail:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidEmail)
+		case domain.ErrRoleNotFound:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.RoleNotFound)
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
 		default:
 			utils.RespondWithErrorJSON(w, status.InternalServerError, fmt.Sprintf("Error creating admin: %v", err))
 		}
@@ -129,8 +146,36 @@ func (h *AdminHandler) UpdateAdminHandler(w http.ResponseWriter, r *http.Request
 
 	updateAdminRequest.ID = int32(id)
 
-	admin, err := h.AdminService.UpdateAdmin(&updateAdminRequest)
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	admin, err := h.AdminService.UpdateAdmin(principal, &updateAdminRequest)
 	if err != nil {
+		if respondWithPasswordError(w, err) {
+			return
+		}
+
+		switch err {
+		case domain.ErrAdminEmailExists:
+			utils.RespondWithErrorJSON(w, status.Conflict, errors.AdminEmailExists)
+			return
+		case domain.ErrInvalidEmail:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidEmail)
+			return
+		case domain.ErrRoleNotFound:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.RoleNotFound)
+			return
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+			return
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+			return
+		}
+
 		slog.Error("Error updating admin: ", utils.Err(err))
 		utils.RespondWithErrorJSON(w, status.InternalServerError, fmt.Sprintf("error updating admin: %v", err))
 		return
@@ -149,7 +194,18 @@ func (h *AdminHandler) DeleteAdminHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.AdminService.DeleteAdmin(int32(id)); err != nil {
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	if err := h.AdminService.DeleteAdmin(principal, int32(id)); err != nil {
+		if err == domain.ErrRoleOutranksCaller {
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+			return
+		}
+
 		slog.Error("Error deleting admin: ", utils.Err(err))
 
 		if strings.Contains(err.Error(), "not found") {
@@ -175,6 +231,159 @@ func (h *AdminHandler) SearchAdminsHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	adminStatus := r.URL.Query().Get("status")
+	if !domain.IsAdminStatus(adminStatus) {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidAdminStatus)
+		return
+	}
+
+	sort, ok := parseSort(w, r, domain.AdminSortFields)
+	if !ok {
+		return
+	}
+
+	page, ok := parsePageRequest(w, r, sort)
+	if !ok {
+		return
+	}
+
+	admins, info, err := h.AdminService.SearchAdmins(query, adminStatus, sort, page)
+	if err == domain.ErrInvalidCursor {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidCursor)
+		return
+	}
+	if err != nil {
+		slog.Error("Error searching admins: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	response := struct {
+		Admins *domain.AdminsList `json:"admins"`
+		pageMetadata
+	}{
+		Admins:       admins,
+		pageMetadata: newPageMetadata(w, r, page, info),
+	}
+
+	utils.RespondWithJSON(w, status.OK, response)
+}
+
+func (h *AdminHandler) UnlockAdminHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	if err := h.AdminService.UnlockAdmin(principal, int32(id), clientInfo(r)); err != nil {
+		slog.Error("Error unlocking admin: ", utils.Err(err))
+
+		switch err {
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+			return
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+			return
+		}
+
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Admin unlocked successfully",
+	})
+}
+
+func (h *AdminHandler) DisableAdminHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	var request domain.DisableAdminRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Reason == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.DisableReasonRequired)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	if err := h.AdminService.DisableAdmin(principal, int32(id), request.Reason, clientInfo(r)); err != nil {
+		switch err {
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+		case domain.ErrCannotDisableSelf:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.CannotDisableSelf)
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+		default:
+			slog.Error("Error disabling admin: ", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Admin disabled successfully",
+	})
+}
+
+func (h *AdminHandler) EnableAdminHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	if err := h.AdminService.EnableAdmin(principal, int32(id), clientInfo(r)); err != nil {
+		switch err {
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+		default:
+			slog.Error("Error enabling admin: ", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Admin enabled successfully",
+	})
+}
+
+func (h *AdminHandler) GetSecurityEventsHandler(w http.ResponseWriter, r *http.Request) {
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page <= 0 {
 		page = 1
@@ -185,31 +394,76 @@ func (h *AdminHandler) SearchAdminsHandler(w http.ResponseWriter, r *http.Reques
 		pageSize = 8 // Default page size
 	}
 
-	admins, err := h.AdminService.SearchAdmins(query, page, pageSize)
+	events, err := h.AdminService.GetSecurityEvents(r.URL.Query().Get("type"), page, pageSize)
 	if err != nil {
-		slog.Error("Error searching admins: ", utils.Err(err))
+		slog.Error("Error getting security events: ", utils.Err(err))
 		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
 		return
 	}
 
-	previousPage := page - 1
-	if previousPage < 1 {
-		previousPage = 1
+	utils.RespondWithJSON(w, status.OK, events)
+}
+
+func (h *AdminHandler) GetLoginEventsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
 	}
 
-	nextPage := page + 1
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
 
-	response := struct {
-		Admins      *domain.AdminsList `json:"admins"`
-		CurrentPage int                `json:"currentPage"`
-		PrevPage    int                `json:"previousPage"`
-		NextPage    int                `json:"nextPage"`
-	}{
-		Admins:      admins,
-		CurrentPage: page,
-		PrevPage:    previousPage,
-		NextPage:    nextPage,
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 8 // Default page size
 	}
 
-	utils.RespondWithJSON(w, status.OK, response)
+	events, err := h.AdminService.GetLoginEvents(int32(id), page, pageSize)
+	if err != nil {
+		if err == domain.ErrAdminNotFound {
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+			return
+		}
+
+		slog.Error("Error getting login events: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, events)
+}
+
+// GetLoginFailuresHandler lists failed logins of the last 24 hours, or since the "since" parameter
+func (h *AdminHandler) GetLoginFailuresHandler(w http.ResponseWriter, r *http.Request) {
+	since := time.Now().Add(-24 * time.Hour)
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidURLParameters)
+			return
+		}
+		since = parsed
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 8 // Default page size
+	}
+
+	events, err := h.AdminService.GetLoginFailures(since, page, pageSize)
+	if err != nil {
+		slog.Error("Error getting login failures: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, events)
 }
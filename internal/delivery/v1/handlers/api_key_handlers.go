@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	APIKeyService *service.APIKeyService
+}
+
+func (h *APIKeyHandler) GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := h.APIKeyService.GetAPIKeys()
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, apiKeys)
+}
+
+func (h *APIKeyHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.APIKeyNameRequired)
+		return
+	}
+
+	if request.ExpiresAt != nil && request.ExpiresAt.Before(time.Now()) {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	apiKey, err := h.APIKeyService.CreateAPIKey(principal, &request)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidScope:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidAPIKeyScope)
+			return
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.APIKeyScopeNotHeld)
+			return
+		}
+
+		slog.Error("Error creating api key: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, apiKey)
+}
+
+func (h *APIKeyHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	if err := h.APIKeyService.RevokeAPIKey(int32(id)); err != nil {
+		if err == domain.ErrAPIKeyNotFound {
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.APIKeyNotFound)
+			return
+		}
+
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "API key revoked successfully",
+	})
+}
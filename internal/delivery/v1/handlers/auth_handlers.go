@@ -3,8 +3,12 @@ package handlers
 import (
 	"encoding/json"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"user-admin/internal/config"
+	"user-admin/internal/delivery/v1/middleware"
 	"user-admin/internal/domain"
 	"user-admin/internal/service"
 	"user-admin/pkg/lib/errors"
@@ -12,11 +16,13 @@ import (
 	"user-admin/pkg/lib/utils"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type AdminAuthHandler struct {
 	AdminAuthService service.AdminAuthService
 	Router           *chi.Mux
+	Cookies          config.SessionCookies
 }
 
 type LoginRequest struct {
@@ -25,8 +31,14 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken            string `json:"access_token,omitempty"`
+	RefreshToken           string `json:"refresh_token,omitempty"`
+	MFARequired            bool   `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired  bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken               string `json:"mfa_token,omitempty"`
+	PasswordChangeRequired bool   `json:"password_change_required,omitempty"`
+	PasswordChangeToken    string `json:"password_change_token,omitempty"`
+	CSRFToken              string `json:"csrf_token,omitempty"`
 }
 
 type StatusMessage struct {
@@ -42,11 +54,18 @@ func (h *AdminAuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	accessToken, refreshToken, err := h.AdminAuthService.LoginAdmin(loginRequest.Username, loginRequest.Password)
+	result, err := h.AdminAuthService.LoginAdmin(loginRequest.Username, loginRequest.Password, clientInfo(r))
 	if err != nil {
+		if throttled, ok := err.(*domain.LoginThrottledError); ok {
+			respondWithLoginThrottled(w, throttled)
+			return
+		}
+
 		switch err {
-		case domain.ErrAdminNotFound:
-			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+		case domain.ErrLocalLoginDisabled:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.LocalLoginDisabled)
+		case domain.ErrAdminNotFound, domain.ErrInvalidCredentials:
+			utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidCredentials)
 		default:
 			slog.Error("Error during login:", utils.Err(err))
 			utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidCredentials)
@@ -54,29 +73,65 @@ func (h *AdminAuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	loginResponse := LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}
-
-	utils.RespondWithJSON(w, status.OK, loginResponse)
+	h.respondWithLoginResult(w, r, result)
 }
 
 func (h *AdminAuthHandler) RefreshTokensHandler(w http.ResponseWriter, r *http.Request) {
 	refreshToken := extractTokenFromHeader(r)
+
+	// Without a bearer token the refresh token may come from the session cookie
+	fromCookie := false
+	if refreshToken == "" {
+		if cookie, err := r.Cookie(middleware.RefreshTokenCookie); err == nil && cookie.Value != "" {
+			if !middleware.ValidCSRFToken(r) {
+				utils.RespondWithErrorJSON(w, status.Forbidden, errors.InvalidCSRFToken)
+				return
+			}
+
+			refreshToken = cookie.Value
+			fromCookie = true
+		}
+	}
+
 	if refreshToken == "" {
 		slog.Error("Refresh token is not provided")
 		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.RefreshTokenNotProvided)
 		return
 	}
 
-	newAccessToken, newRefreshToken, err := h.AdminAuthService.RefreshTokens(refreshToken)
+	newAccessToken, newRefreshToken, err := h.AdminAuthService.RefreshTokens(refreshToken, clientInfo(r))
 	if err != nil {
+		switch err {
+		case domain.ErrAccessDenied:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.AccessPolicyDenied)
+			return
+		case domain.ErrAdminDisabled:
+			if fromCookie {
+				h.clearSessionCookies(w)
+			}
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.AdminDisabled)
+			return
+		}
+
 		slog.Error("Error refreshing tokens:", utils.Err(err))
+		if fromCookie {
+			h.clearSessionCookies(w)
+		}
 		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidRefreshToken)
 		return
 	}
 
+	if fromCookie {
+		csrfToken, err := h.setSessionCookies(w, newAccessToken, newRefreshToken)
+		if err != nil {
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+			return
+		}
+
+		utils.RespondWithJSON(w, status.OK, CSRFTokenResponse{CSRFToken: csrfToken})
+		return
+	}
+
 	utils.RespondWithJSON(w, status.OK, map[string]string{
 		"access_token":  newAccessToken,
 		"refresh_token": newRefreshToken,
@@ -84,13 +139,26 @@ func (h *AdminAuthHandler) RefreshTokensHandler(w http.ResponseWriter, r *http.R
 }
 
 func (h *AdminAuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	var requestData map[string]string
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
-		return
+	var refreshToken string
+
+	if cookie, err := r.Cookie(middleware.RefreshTokenCookie); err == nil && cookie.Value != "" {
+		if !middleware.ValidCSRFToken(r) {
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.InvalidCSRFToken)
+			return
+		}
+
+		refreshToken = cookie.Value
+		h.clearSessionCookies(w)
+	} else {
+		var requestData map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+			return
+		}
+
+		refreshToken = requestData["refresh_token"]
 	}
 
-	refreshToken := requestData["refresh_token"]
 	if refreshToken == "" {
 		utils.RespondWithErrorJSON(w, status.BadRequest, errors.RefreshTokenNotProvided)
 		return
@@ -110,10 +178,177 @@ func (h *AdminAuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request)
 	utils.RespondWithJSON(w, status.OK, response)
 }
 
+func (h *AdminAuthHandler) GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, sessionID, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	sessions, err := h.AdminAuthService.GetSessions(adminID, sessionID)
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, sessions)
+}
+
+func (h *AdminAuthHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	h.revokeSession(w, r, func(sessionID string) error {
+		return h.AdminAuthService.RevokeSession(adminID, sessionID)
+	})
+}
+
+func (h *AdminAuthHandler) GetAdminSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	_, sessionID, _ := currentAdmin(r)
+
+	sessions, err := h.AdminAuthService.GetSessions(int32(adminID), sessionID)
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, sessions)
+}
+
+func (h *AdminAuthHandler) RevokeAdminSessionHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	h.revokeSession(w, r, func(sessionID string) error {
+		return h.AdminAuthService.RevokeAdminSession(principal, int32(adminID), sessionID)
+	})
+}
+
+func (h *AdminAuthHandler) revokeSession(w http.ResponseWriter, r *http.Request, revoke func(sessionID string) error) {
+	sessionID := chi.URLParam(r, "sessionID")
+	if _, err := uuid.Parse(sessionID); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidSessionID)
+		return
+	}
+
+	err := revoke(sessionID)
+	if err != nil {
+		switch err {
+		case domain.ErrSessionNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.SessionNotFound)
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+		default:
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Session revoked successfully",
+	})
+}
+
+// ImpersonateAdminHandler hands the caller a short-lived access token of another admin
+func (h *AdminAuthHandler) ImpersonateAdminHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok || !principal.IsAdmin() {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	impersonation, err := h.AdminAuthService.Impersonate(principal, int32(adminID), clientInfo(r))
+	if err != nil {
+		switch err {
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+		case domain.ErrImpersonationNotAllowed:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.ImpersonationNotAllowed)
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+		case domain.ErrAdminDisabled:
+			utils.RespondWithErrorJSON(w, status.Conflict, errors.AdminDisabled)
+		default:
+			slog.Error("Error impersonating admin:", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, impersonation)
+}
+
+// respondWithLoginThrottled answers with 423 for locked accounts and 429 while backing off
+func respondWithLoginThrottled(w http.ResponseWriter, err *domain.LoginThrottledError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+
+	if err.Locked {
+		utils.RespondWithErrorJSON(w, status.Locked, errors.AccountLocked)
+		return
+	}
+
+	utils.RespondWithErrorJSON(w, status.TooManyRequests, errors.TooManyLoginAttempts)
+}
+
+func newLoginResponse(result *domain.LoginResult) LoginResponse {
+	return LoginResponse{
+		AccessToken:            result.AccessToken,
+		RefreshToken:           result.RefreshToken,
+		MFARequired:            result.MFARequired,
+		MFAEnrollmentRequired:  result.MFAEnrollmentRequired,
+		MFAToken:               result.MFAToken,
+		PasswordChangeRequired: result.PasswordChangeRequired,
+		PasswordChangeToken:    result.PasswordChangeToken,
+	}
+}
+
+func clientInfo(r *http.Request) domain.ClientInfo {
+	return domain.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: utils.ClientIP(r),
+	}
+}
+
+// currentAdmin returns the admin ID and session ID of the admin the request was made by
+func currentAdmin(r *http.Request) (int32, string, bool) {
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok || !principal.IsAdmin() {
+		return 0, "", false
+	}
+
+	return principal.AdminID, principal.SessionID, true
+}
+
 func extractTokenFromHeader(r *http.Request) string {
 	bearerToken := r.Header.Get("Authorization")
 	if bearerToken == "" {
-		slog.Error("Authorization header not found")
 		return ""
 	}
 
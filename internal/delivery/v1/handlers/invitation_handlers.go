@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type InvitationHandler struct {
+	InvitationService *service.InvitationService
+}
+
+func (h *InvitationHandler) GetInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.InvitationService.GetInvitations()
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, invitations)
+}
+
+func (h *InvitationHandler) CreateInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	if request.Username == "" || request.Role == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.UsernameAndRoleRequired)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	invitation, err := h.InvitationService.CreateInvitation(principal, &request, clientInfo(r))
+	if err != nil {
+		switch err {
+		case domain.ErrAdminAlreadyExists:
+			utils.RespondWithErrorJSON(w, status.Conflict, "Admin with the same username already exists")
+		case domain.ErrInvitationExists:
+			utils.RespondWithErrorJSON(w, status.Conflict, errors.InvitationExists)
+		case domain.ErrAdminEmailExists:
+			utils.RespondWithErrorJSON(w, status.Conflict, errors.AdminEmailExists)
+		case domain.ErrInvalidEmail:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidEmail)
+		case domain.ErrRoleNotFound:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.RoleNotFound)
+		case domain.ErrRoleOutranksCaller:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+		default:
+			slog.Error("Error creating invitation: ", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, invitation)
+}
+
+func (h *InvitationHandler) ResendInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	invitation, err := h.InvitationService.ResendInvitation(int32(id))
+	if err != nil {
+		respondWithInvitationError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, invitation)
+}
+
+func (h *InvitationHandler) RevokeInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	revokedBy, _, _ := currentAdmin(r)
+
+	if err := h.InvitationService.RevokeInvitation(int32(id), revokedBy, clientInfo(r)); err != nil {
+		respondWithInvitationError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Invitation revoked successfully",
+	})
+}
+
+func (h *InvitationHandler) AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Password == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	admin, err := h.InvitationService.AcceptInvitation(chi.URLParam(r, "token"), request.Password, clientInfo(r))
+	if err != nil {
+		if respondWithPasswordError(w, err) {
+			return
+		}
+
+		switch err {
+		case domain.ErrInvalidInvitationToken:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidInvitationToken)
+		case domain.ErrAdminAlreadyExists:
+			utils.RespondWithErrorJSON(w, status.Conflict, "Admin with the same username already exists")
+		default:
+			slog.Error("Error accepting invitation: ", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, admin)
+}
+
+func respondWithInvitationError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrInvitationNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.InvitationNotFound)
+	case domain.ErrInvitationNotPending:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.InvitationNotPending)
+	default:
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
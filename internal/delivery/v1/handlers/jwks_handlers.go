@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+	"user-admin/pkg/jwtkeys"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+type JWKSHandler struct {
+	KeySet *jwtkeys.KeySet
+}
+
+// GetJWKSHandler publishes the public keys that other services use to verify access tokens
+func (h *JWKSHandler) GetJWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.RespondWithJSON(w, status.OK, h.KeySet.JWKS())
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type VerifyMFARequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type ConfirmMFAEnrollmentResponse struct {
+	RecoveryCodes          []string `json:"recovery_codes"`
+	AccessToken            string   `json:"access_token,omitempty"`
+	RefreshToken           string   `json:"refresh_token,omitempty"`
+	PasswordChangeRequired bool     `json:"password_change_required,omitempty"`
+	PasswordChangeToken    string   `json:"password_change_token,omitempty"`
+	CSRFToken              string   `json:"csrf_token,omitempty"`
+}
+
+func (h *AdminAuthHandler) VerifyMFAHandler(w http.ResponseWriter, r *http.Request) {
+	var request VerifyMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	if request.MFAToken == "" || (request.Code == "" && request.RecoveryCode == "") {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	result, err := h.AdminAuthService.VerifyMFA(request.MFAToken, request.Code, request.RecoveryCode, clientInfo(r))
+	if err != nil {
+		respondWithMFAError(w, err)
+		return
+	}
+
+	h.respondWithLoginResult(w, r, result)
+}
+
+func (h *AdminAuthHandler) EnrollMFAHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	enrollment, err := h.AdminAuthService.EnrollMFA(adminID)
+	if err != nil {
+		respondWithMFAError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, enrollment)
+}
+
+func (h *AdminAuthHandler) ConfirmMFAEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	var request MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Code == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	recoveryCodes, err := h.AdminAuthService.ConfirmMFAEnrollment(adminID, request.Code)
+	if err != nil {
+		respondWithMFAError(w, err)
+		return
+	}
+
+	response := ConfirmMFAEnrollmentResponse{RecoveryCodes: recoveryCodes}
+
+	// Enrolling with the restricted token of a pending login also finishes that login
+	if principal, _ := middleware.GetPrincipal(r.Context()); principal.Purpose == domain.MFAEnrollmentTokenPurpose {
+		result, err := h.AdminAuthService.CompleteLogin(adminID, domain.LoginMethodTOTP, clientInfo(r))
+		if err != nil {
+			respondWithMFAError(w, err)
+			return
+		}
+
+		switch {
+		case result.PasswordChangeRequired:
+			// The login goes on with a password change, there is no session for cookies yet
+			response.PasswordChangeRequired = true
+			response.PasswordChangeToken = result.PasswordChangeToken
+		case h.wantsCookies(r):
+			response.CSRFToken, err = h.setSessionCookies(w, result.AccessToken, result.RefreshToken)
+			if err != nil {
+				utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+				return
+			}
+		default:
+			response.AccessToken = result.AccessToken
+			response.RefreshToken = result.RefreshToken
+		}
+	}
+
+	utils.RespondWithJSON(w, status.OK, response)
+}
+
+func (h *AdminAuthHandler) DisableMFAHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(r.Context())
+
+	var request MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Code == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	if err := h.AdminAuthService.DisableMFA(adminID, principal.Role, request.Code); err != nil {
+		respondWithMFAError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+func (h *AdminAuthHandler) ResetAdminMFAHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	if err := h.AdminAuthService.ResetMFA(principal, int32(adminID), clientInfo(r)); err != nil {
+		respondWithMFAError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Two-factor authentication reset successfully",
+	})
+}
+
+func (h *AdminAuthHandler) GetRoleMFARequirementsHandler(w http.ResponseWriter, r *http.Request) {
+	requirements, err := h.AdminAuthService.GetRoleMFARequirements()
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, requirements)
+}
+
+func (h *AdminAuthHandler) SetRoleMFARequirementHandler(w http.ResponseWriter, r *http.Request) {
+	var requirement domain.RoleMFARequirement
+	if err := json.NewDecoder(r.Body).Decode(&requirement); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	requirement.Role = chi.URLParam(r, "role")
+
+	if err := h.AdminAuthService.SetRoleMFARequirement(&requirement); err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, requirement)
+}
+
+func respondWithMFAError(w http.ResponseWriter, err error) {
+	if throttled, ok := err.(*domain.LoginThrottledError); ok {
+		respondWithLoginThrottled(w, throttled)
+		return
+	}
+
+	switch err {
+	case domain.ErrInvalidMFAToken:
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidMFAToken)
+	case domain.ErrInvalidMFACode:
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidMFACode)
+	case domain.ErrMFANotEnrolled:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.MFANotEnrolled)
+	case domain.ErrMFAAlreadyEnabled:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.MFAAlreadyEnabled)
+	case domain.ErrMFARequired:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.MFARequiredForRole)
+	case domain.ErrAdminNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+	case domain.ErrRoleOutranksCaller:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+	case domain.ErrAccessDenied:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.AccessPolicyDenied)
+	case domain.ErrAdminDisabled:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.AdminDisabled)
+	default:
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
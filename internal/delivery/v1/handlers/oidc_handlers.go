@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+type OIDCHandler struct {
+	OIDCService *service.OIDCService
+}
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// LoginHandler answers with the identity provider URL the browser has to be sent to
+func (h *OIDCHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	authorizationURL, err := h.OIDCService.StartLogin(r.Context())
+	if err != nil {
+		respondWithOIDCError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, OIDCLoginResponse{AuthorizationURL: authorizationURL})
+}
+
+// CallbackHandler takes the code and state either from the query of the provider's
+// redirect or, when the admin panel received the redirect itself, from a JSON body
+func (h *OIDCHandler) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	request := OIDCCallbackRequest{
+		Code:  r.URL.Query().Get("code"),
+		State: r.URL.Query().Get("state"),
+	}
+
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+			return
+		}
+	}
+
+	if providerError := r.URL.Query().Get("error"); providerError != "" {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.SSOProviderUnavailable+": "+providerError)
+		return
+	}
+
+	if request.Code == "" || request.State == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	result, err := h.OIDCService.CompleteLogin(r.Context(), request.Code, request.State, clientInfo(r))
+	if err != nil {
+		respondWithOIDCError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, newLoginResponse(result))
+}
+
+func respondWithOIDCError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrInvalidOIDCState:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidSSOState)
+	case domain.ErrOIDCNoRole:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.SSONoRole)
+	case domain.ErrOIDCNotProvisioned:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.SSONotProvisioned)
+	case domain.ErrOIDCMissingClaim:
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.SSOMissingClaim)
+	case domain.ErrOIDCProviderUnavailable:
+		utils.RespondWithErrorJSON(w, status.BadGateway, errors.SSOProviderUnavailable)
+	case domain.ErrAccessDenied:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.AccessPolicyDenied)
+	case domain.ErrAdminDisabled:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.AdminDisabled)
+	default:
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
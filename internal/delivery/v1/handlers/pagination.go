@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+// pageMetadata describes a page of a listing. Page numbers are left out in cursor mode,
+// nextPage and nextCursor on the last page.
+type pageMetadata struct {
+	CurrentPage    int    `json:"currentPage,omitempty"`
+	PrevPage       int    `json:"previousPage,omitempty"`
+	NextPage       int    `json:"nextPage,omitempty"`
+	NextCursor     string `json:"nextCursor,omitempty"`
+	Total          *int64 `json:"total,omitempty"`
+	TotalEstimated bool   `json:"totalEstimated,omitempty"`
+}
+
+// parsePageRequest reads page, pageSize, cursor and total and answers with 400 when the cursor or total
+// is invalid. A cursor switches the listing to keyset mode and takes precedence over the page number.
+func parsePageRequest(w http.ResponseWriter, r *http.Request, sort domain.Sort) (domain.PageRequest, bool) {
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 8 // Default page size
+	}
+
+	request := domain.PageRequest{Page: page, PageSize: pageSize, Total: query.Get("total")}
+
+	if request.Total != "" && request.Total != domain.TotalExact && request.Total != domain.TotalEstimate {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidTotalMode)
+		return request, false
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := domain.DecodeCursor(value, sort)
+		if err != nil {
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidCursor)
+			return request, false
+		}
+		request.Cursor = cursor
+	}
+
+	return request, true
+}
+
+// newPageMetadata describes the page and links it to its neighbours with an RFC 8288 Link header
+func newPageMetadata(w http.ResponseWriter, r *http.Request, request domain.PageRequest, info *domain.PageInfo) pageMetadata {
+	metadata := pageMetadata{
+		NextCursor:     info.NextCursor,
+		Total:          info.Total,
+		TotalEstimated: info.TotalEstimated,
+	}
+
+	links := []string{pageLink(r, "first", map[string]string{"cursor": "", "page": ""})}
+
+	if request.Cursor == nil {
+		metadata.CurrentPage = request.Page
+		metadata.PrevPage = request.Page - 1
+		if metadata.PrevPage < 1 {
+			metadata.PrevPage = 1
+		}
+
+		if request.Page > 1 {
+			links = append(links, pageLink(r, "prev", map[string]string{"page": strconv.Itoa(request.Page - 1)}))
+		}
+
+		if info.HasMore {
+			metadata.NextPage = request.Page + 1
+			links = append(links, pageLink(r, "next", map[string]string{"page": strconv.Itoa(request.Page + 1)}))
+		}
+
+		if info.Total != nil && !info.TotalEstimated {
+			last := int((*info.Total + int64(request.PageSize) - 1) / int64(request.PageSize))
+			if last < 1 {
+				last = 1
+			}
+			links = append(links, pageLink(r, "last", map[string]string{"page": strconv.Itoa(last)}))
+		}
+	} else if info.HasMore {
+		links = append(links, pageLink(r, "next", map[string]string{"cursor": info.NextCursor}))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+
+	return metadata
+}
+
+// pageLink points to the listing with the same parameters apart from the changed ones, empty values are removed
+func pageLink(r *http.Request, rel string, changes map[string]string) string {
+	query := r.URL.Query()
+	for name, value := range changes {
+		if value == "" {
+			query.Del(name)
+		} else {
+			query.Set(name, value)
+		}
+	}
+
+	target := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return "<" + target.String() + `>; rel="` + rel + `"`
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-admin/internal/domain"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	sort := domain.Sort{{Field: "last_name"}}
+	cursor := domain.EncodeCursor(domain.Cursor{Sort: sort.String(), Values: []string{"a", "1"}})
+
+	tests := []struct {
+		query      string
+		wantOK     bool
+		wantPage   int
+		wantSize   int
+		wantCursor bool
+	}{
+		{"", true, 1, 8, false},
+		{"page=3&pageSize=20&total=exact", true, 3, 20, false},
+		{"page=-1&pageSize=abc", true, 1, 8, false},
+		{"page=3&cursor=" + cursor, true, 3, 8, true},
+		{"total=all", false, 0, 0, false},
+		{"cursor=garbage", false, 0, 0, false},
+		{"cursor=" + domain.EncodeCursor(domain.Cursor{Sort: "-last_name", Values: []string{"a", "1"}}), false, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		request, ok := parsePageRequest(w, httptest.NewRequest(http.MethodGet, "/users?"+test.query, nil), sort)
+
+		if ok != test.wantOK {
+			t.Errorf("parsePageRequest(%q) ok = %v, want %v", test.query, ok, test.wantOK)
+			continue
+		}
+		if !ok {
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("parsePageRequest(%q) answered %d, want 400", test.query, w.Code)
+			}
+			continue
+		}
+		if request.Page != test.wantPage || request.PageSize != test.wantSize || (request.Cursor != nil) != test.wantCursor {
+			t.Errorf("parsePageRequest(%q) = %+v", test.query, request)
+		}
+	}
+}
+
+func TestNewPageMetadataLinks(t *testing.T) {
+	total := int64(25)
+
+	tests := []struct {
+		name     string
+		query    string
+		request  domain.PageRequest
+		info     domain.PageInfo
+		wantLink string
+	}{
+		{
+			name:     "first page",
+			query:    "pageSize=10&sort=last_name",
+			request:  domain.PageRequest{Page: 1, PageSize: 10},
+			info:     domain.PageInfo{HasMore: true, NextCursor: "abc", Total: &total},
+			wantLink: `</users?pageSize=10&sort=last_name>; rel="first", </users?page=2&pageSize=10&sort=last_name>; rel="next", </users?page=3&pageSize=10&sort=last_name>; rel="last"`,
+		},
+		{
+			name:     "last page",
+			query:    "page=3&pageSize=10",
+			request:  domain.PageRequest{Page: 3, PageSize: 10},
+			info:     domain.PageInfo{},
+			wantLink: `</users?pageSize=10>; rel="first", </users?page=2&pageSize=10>; rel="prev"`,
+		},
+		{
+			name:     "cursor",
+			query:    "cursor=abc&pageSize=10",
+			request:  domain.PageRequest{Page: 1, PageSize: 10, Cursor: &domain.Cursor{}},
+			info:     domain.PageInfo{HasMore: true, NextCursor: "def"},
+			wantLink: `</users?pageSize=10>; rel="first", </users?cursor=def&pageSize=10>; rel="next"`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		metadata := newPageMetadata(w, httptest.NewRequest(http.MethodGet, "/users?"+test.query, nil), test.request, &test.info)
+
+		if link := w.Header().Get("Link"); link != test.wantLink {
+			t.Errorf("%s: Link = %s, want %s", test.name, link, test.wantLink)
+		}
+		if test.request.Cursor != nil && metadata.CurrentPage != 0 {
+			t.Errorf("%s: page numbers reported in cursor mode: %+v", test.name, metadata)
+		}
+		if metadata.NextCursor != test.info.NextCursor {
+			t.Errorf("%s: nextCursor = %q, want %q", test.name, metadata.NextCursor, test.info.NextCursor)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/password"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordPolicyErrorResponse struct {
+	Status     int      `json:"code"`
+	Message    string   `json:"message"`
+	Violations []string `json:"violations"`
+}
+
+func (h *AdminAuthHandler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	var request ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	if request.CurrentPassword == "" || request.NewPassword == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	result, err := h.AdminAuthService.ChangePassword(adminID, request.CurrentPassword, request.NewPassword, clientInfo(r))
+	if err != nil {
+		if respondWithPasswordError(w, err) {
+			return
+		}
+
+		if throttled, ok := err.(*domain.LoginThrottledError); ok {
+			respondWithLoginThrottled(w, throttled)
+			return
+		}
+
+		switch err {
+		case domain.ErrInvalidCredentials:
+			utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidCredentials)
+		case domain.ErrAdminNotFound:
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+		case domain.ErrLocalLoginDisabled:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.LocalLoginDisabled)
+		default:
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	h.respondWithLoginResult(w, r, result)
+}
+
+func (h *AdminAuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var request ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Email == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	err := h.AdminAuthService.ForgotPassword(request.Email, clientInfo(r))
+	if err != nil {
+		if throttled, ok := err.(*domain.LoginThrottledError); ok {
+			respondWithLoginThrottled(w, throttled)
+			return
+		}
+
+		if err == domain.ErrLocalLoginDisabled {
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.LocalLoginDisabled)
+			return
+		}
+
+		slog.Error("Error requesting password reset:", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	// Same answer whether or not the email belongs to an admin
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "If the email belongs to an admin, a password reset link has been sent",
+	})
+}
+
+func (h *AdminAuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var request ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	if request.Token == "" || request.NewPassword == "" {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestFormat)
+		return
+	}
+
+	err := h.AdminAuthService.ResetPassword(request.Token, request.NewPassword, clientInfo(r))
+	if err != nil {
+		if respondWithPasswordError(w, err) {
+			return
+		}
+
+		if throttled, ok := err.(*domain.LoginThrottledError); ok {
+			respondWithLoginThrottled(w, throttled)
+			return
+		}
+
+		switch err {
+		case domain.ErrInvalidResetToken:
+			utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidResetToken)
+		case domain.ErrLocalLoginDisabled:
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.LocalLoginDisabled)
+		default:
+			slog.Error("Error resetting password:", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Password has been reset",
+	})
+}
+
+// respondWithPasswordError answers policy and history violations, it reports whether err was one of them
+func respondWithPasswordError(w http.ResponseWriter, err error) bool {
+	if policyErr, ok := err.(*password.PolicyError); ok {
+		utils.RespondWithJSON(w, status.BadRequest, PasswordPolicyErrorResponse{
+			Status:     status.BadRequest,
+			Message:    errors.PasswordPolicyViolation,
+			Violations: policyErr.Violations,
+		})
+		return true
+	}
+
+	if err == domain.ErrPasswordReused {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.PasswordReused)
+		return true
+	}
+
+	return false
+}
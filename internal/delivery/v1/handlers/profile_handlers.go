@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+type ProfileHandler struct {
+	ProfileService *service.ProfileService
+}
+
+func (h *ProfileHandler) GetProfileHandler(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok || !principal.IsAdmin() {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	profile, err := h.ProfileService.GetProfile(principal)
+	if err != nil {
+		if err == domain.ErrAdminNotFound {
+			utils.RespondWithErrorJSON(w, status.NotFound, errors.AdminNotFound)
+			return
+		}
+
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, profile)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RoleHandler struct {
+	RoleService *service.RoleService
+}
+
+func (h *RoleHandler) GetRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.RoleService.GetRoles()
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, roles)
+}
+
+func (h *RoleHandler) GetPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, status.OK, h.RoleService.GetPermissions())
+}
+
+func (h *RoleHandler) GetRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role, err := h.RoleService.GetRole(chi.URLParam(r, "name"))
+	if err != nil {
+		respondWithRoleError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, role)
+}
+
+func (h *RoleHandler) CreateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	role, err := h.RoleService.CreateRole(principal, &request)
+	if err != nil {
+		respondWithRoleError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, role)
+}
+
+func (h *RoleHandler) UpdateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRequestBody)
+		return
+	}
+
+	request.Name = chi.URLParam(r, "name")
+
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	role, err := h.RoleService.UpdateRole(principal, &request)
+	if err != nil {
+		respondWithRoleError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, role)
+}
+
+func (h *RoleHandler) DeleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+		return
+	}
+
+	if err := h.RoleService.DeleteRole(principal, chi.URLParam(r, "name")); err != nil {
+		respondWithRoleError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "Role deleted successfully",
+	})
+}
+
+func respondWithRoleError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRoleNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.RoleNotFound)
+	case domain.ErrRoleAlreadyExists:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.RoleAlreadyExists)
+	case domain.ErrRoleProtected:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleProtected)
+	case domain.ErrRoleInUse:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.RoleInUse)
+	case domain.ErrInvalidRoleName:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidRoleName)
+	case domain.ErrUnknownPermission:
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.UnknownPermission)
+	case domain.ErrRoleOutranksCaller:
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.RoleOutranksCaller)
+	default:
+		slog.Error("Error managing role:", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+const (
+	// sessionModeHeader lets the admin panel ask for the tokens of a login to be set as cookies
+	sessionModeHeader = "X-Session-Mode"
+	sessionModeCookie = "cookie"
+
+	// refreshCookieMaxAge matches the lifetime of refresh tokens, the access cookie lasts as
+	// long as the browser session and is replaced on every refresh
+	refreshCookieMaxAge = 7 * 24 * time.Hour
+	refreshCookiePath   = "/auth"
+	csrfTokenBytes      = 32
+)
+
+type CSRFTokenResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// wantsCookies reports whether the tokens issued for the request go into cookies instead of the body
+func (h *AdminAuthHandler) wantsCookies(r *http.Request) bool {
+	return h.Cookies.CookieMode && strings.EqualFold(r.Header.Get(sessionModeHeader), sessionModeCookie)
+}
+
+// respondWithLoginResult answers a finished or pending login. In cookie mode the tokens of a
+// finished login are set as cookies and only the CSRF token is part of the body.
+func (h *AdminAuthHandler) respondWithLoginResult(w http.ResponseWriter, r *http.Request, result *domain.LoginResult) {
+	response := newLoginResponse(result)
+
+	if h.wantsCookies(r) && result.AccessToken != "" {
+		csrfToken, err := h.setSessionCookies(w, result.AccessToken, result.RefreshToken)
+		if err != nil {
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+			return
+		}
+
+		response.AccessToken = ""
+		response.RefreshToken = ""
+		response.CSRFToken = csrfToken
+	}
+
+	utils.RespondWithJSON(w, status.OK, response)
+}
+
+// setSessionCookies stores the tokens in HttpOnly cookies and returns the new CSRF token,
+// which is also set as a script-readable cookie for the double-submit check
+func (h *AdminAuthHandler) setSessionCookies(w http.ResponseWriter, accessToken, refreshToken string) (string, error) {
+	buf := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	csrfToken := base64.RawURLEncoding.EncodeToString(buf)
+
+	http.SetCookie(w, h.sessionCookie(middleware.AccessTokenCookie, accessToken, "/", 0, true))
+	http.SetCookie(w, h.sessionCookie(middleware.RefreshTokenCookie, refreshToken, refreshCookiePath, refreshCookieMaxAge, true))
+	http.SetCookie(w, h.sessionCookie(middleware.CSRFTokenCookie, csrfToken, "/", 0, false))
+
+	return csrfToken, nil
+}
+
+func (h *AdminAuthHandler) clearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, h.sessionCookie(middleware.AccessTokenCookie, "", "/", -1, true))
+	http.SetCookie(w, h.sessionCookie(middleware.RefreshTokenCookie, "", refreshCookiePath, -1, true))
+	http.SetCookie(w, h.sessionCookie(middleware.CSRFTokenCookie, "", "/", -1, false))
+}
+
+// sessionCookie builds a cookie with the configured attributes. A zero maxAge makes a
+// browser session cookie, a negative one deletes the cookie.
+func (h *AdminAuthHandler) sessionCookie(name, value, path string, maxAge time.Duration, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.Cookies.CookieDomain,
+		Secure:   !h.Cookies.CookieInsecure,
+		HttpOnly: httpOnly,
+		SameSite: sameSiteMode(h.Cookies.CookieSameSite),
+	}
+
+	switch {
+	case maxAge < 0:
+		cookie.MaxAge = -1
+	case maxAge > 0:
+		cookie.MaxAge = int(maxAge.Seconds())
+	}
+
+	return cookie
+}
+
+func sameSiteMode(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
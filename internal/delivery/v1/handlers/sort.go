@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+// parseSort reads the sort query parameter and answers with 400 when it names a field outside of allowed
+func parseSort(w http.ResponseWriter, r *http.Request, allowed []string) (domain.Sort, bool) {
+	sort, err := domain.ParseSort(r.URL.Query().Get("sort"), allowed)
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, fmt.Sprintf("%s, sortable fields are %s", errors.InvalidSort, strings.Join(allowed, ", ")))
+		return nil, false
+	}
+
+	return sort, true
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 	"user-admin/internal/domain"
 	"user-admin/internal/service"
 	"user-admin/pkg/lib/errors"
@@ -21,26 +24,27 @@ type UserHandler struct {
 }
 
 func (h *UserHandler) GetAllUsersHandler(w http.ResponseWriter, r *http.Request) {
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = 1
+	filter, err := parseUserFilter(r.URL.Query())
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, fmt.Sprintf("%s: %v", errors.InvalidUserFilter, err))
+		return
 	}
 
-	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if err != nil || pageSize <= 0 {
-		pageSize = 8 // Default page size
+	sort, ok := parseSort(w, r, domain.UserSortFields)
+	if !ok {
+		return
 	}
 
-	var previousPage int
-	if page > 1 {
-		previousPage = page - 1
-	} else {
-		previousPage = 1
+	page, ok := parsePageRequest(w, r, sort)
+	if !ok {
+		return
 	}
 
-	nextPage := page + 1
-
-	users, err := h.UserService.GetAllUsers(page, pageSize)
+	users, info, err := h.UserService.GetAllUsers(filter, sort, page)
+	if err == domain.ErrInvalidCursor {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidCursor)
+		return
+	}
 	if err != nil {
 		slog.Error("Error getting users: ", utils.Err(err))
 		http.Error(w, errors.InternalServerError, status.InternalServerError)
@@ -48,15 +52,11 @@ func (h *UserHandler) GetAllUsersHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	response := struct {
-		Users       *domain.UsersList `json:"users"`
-		CurrentPage int               `json:"currentPage"`
-		PrevPage    int               `json:"previousPage"`
-		NextPage    int               `json:"nextPage"`
+		Users *domain.UsersList `json:"users"`
+		pageMetadata
 	}{
-		Users:       users,
-		CurrentPage: page,
-		PrevPage:    previousPage,
-		NextPage:    nextPage,
+		Users:        users,
+		pageMetadata: newPageMetadata(w, r, page, info),
 	}
 
 	utils.RespondWithJSON(w, status.OK, response)
@@ -71,6 +71,10 @@ func (h *UserHandler) GetUserByIDHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	user, err := h.UserService.GetUserByID(int32(id))
+	if err == domain.ErrUserNotFound {
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.UserNotFound)
+		return
+	}
 	if err != nil {
 		slog.Error("Error retrieving user: ", utils.Err(err))
 		utils.RespondWithErrorJSON(w, status.InternalServerError, "Error retrieving user")
@@ -125,6 +129,10 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 	updateUserRequest.ID = int32(id)
 
 	user, err := h.UserService.UpdateUser(&updateUserRequest)
+	if err == domain.ErrUserNotFound {
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.UserNotFound)
+		return
+	}
 	if err != nil {
 		slog.Error("Error updating user: ", utils.Err(err))
 		utils.RespondWithErrorJSON(w, status.InternalServerError, fmt.Sprintf("error updating user: %v", err))
@@ -144,7 +152,14 @@ func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.UserService.DeleteUser(int32(id))
+	// API keys delete users too, they leave deleted_by empty
+	deletedBy, _, _ := currentAdmin(r)
+
+	err = h.UserService.DeleteUser(int32(id), deletedBy)
+	if err == domain.ErrUserNotFound {
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.UserNotFound)
+		return
+	}
 	if err != nil {
 		slog.Error("Error deleting user: ", utils.Err(err))
 		utils.RespondWithErrorJSON(w, status.InternalServerError, fmt.Sprintf("error deleting user: %s", err))
@@ -153,10 +168,93 @@ func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request)
 
 	utils.RespondWithJSON(w, status.OK, StatusMessage{
 		Status:  status.OK,
-		Message: "User deleted successfully",
+		Message: "User moved to the trash",
+	})
+}
+
+func (h *UserHandler) RestoreUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	if err := h.UserService.RestoreUser(int32(id)); err != nil {
+		respondWithTrashError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "User restored successfully",
+	})
+}
+
+func (h *UserHandler) GetDeletedUsersHandler(w http.ResponseWriter, r *http.Request) {
+	page, ok := parsePageRequest(w, r, domain.DeletedUserSort)
+	if !ok {
+		return
+	}
+
+	users, info, err := h.UserService.GetDeletedUsers(page)
+	if err == domain.ErrInvalidCursor {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidCursor)
+		return
+	}
+	if err != nil {
+		slog.Error("Error getting deleted users: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
+
+	response := struct {
+		Users *domain.DeletedUsersList `json:"users"`
+		pageMetadata
+	}{
+		Users:        users,
+		pageMetadata: newPageMetadata(w, r, page, info),
+	}
+
+	utils.RespondWithJSON(w, status.OK, response)
+}
+
+// PurgeUserHandler permanently deletes a user from the trash. Purges are attributed to an admin, never to an API key.
+func (h *UserHandler) PurgeUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidID)
+		return
+	}
+
+	purgedBy, _, ok := currentAdmin(r)
+	if !ok {
+		utils.RespondWithErrorJSON(w, status.Forbidden, errors.APIKeyNotAccepted)
+		return
+	}
+
+	if err := h.UserService.PurgeUser(int32(id), purgedBy, clientInfo(r)); err != nil {
+		respondWithTrashError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, status.OK, StatusMessage{
+		Status:  status.OK,
+		Message: "User permanently deleted",
 	})
 }
 
+func respondWithTrashError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrUserNotFound:
+		utils.RespondWithErrorJSON(w, status.NotFound, errors.UserNotFound)
+	case domain.ErrUserNotDeleted:
+		utils.RespondWithErrorJSON(w, status.Conflict, errors.UserNotDeleted)
+	default:
+		slog.Error("Error managing deleted user: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	}
+}
+
 func (h *UserHandler) BlockUserHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
@@ -199,6 +297,8 @@ func (h *UserHandler) UnblockUserHandler(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// SearchUsersHandler ranks users by relevance unless another sort is given.
+// The query supports quoted phrases and qualifiers such as email:foo, see domain.UserSearchFields.
 func (h *UserHandler) SearchUsersHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("query")
 	if query == "" {
@@ -206,41 +306,142 @@ func (h *UserHandler) SearchUsersHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = 1
+	search, err := domain.ParseUserSearchQuery(query)
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, fmt.Sprintf("%s, qualifiers are %s", errors.InvalidSearchQuery, strings.Join(domain.UserSearchFields, ", ")))
+		return
 	}
 
-	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if err != nil || pageSize <= 0 {
-		pageSize = 8 // Default page size
+	filter, err := parseUserFilter(r.URL.Query())
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.BadRequest, fmt.Sprintf("%s: %v", errors.InvalidUserFilter, err))
+		return
 	}
 
-	users, err := h.UserService.SearchUsers(query, page, pageSize)
-	if err != nil {
-		slog.Error("Error searching users: ", utils.Err(err))
-		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+	sort, ok := parseSort(w, r, domain.UserSearchSortFields)
+	if !ok {
 		return
 	}
+	if sort == nil {
+		sort = domain.SearchByRelevance
+	}
 
-	previousPage := page - 1
-	if previousPage < 1 {
-		previousPage = 1
+	page, ok := parsePageRequest(w, r, sort)
+	if !ok {
+		return
 	}
 
-	nextPage := page + 1
+	users, info, err := h.UserService.SearchUsers(search, filter, sort, page)
+	if err == domain.ErrInvalidCursor {
+		utils.RespondWithErrorJSON(w, status.BadRequest, errors.InvalidCursor)
+		return
+	}
+	if err != nil {
+		slog.Error("Error searching users: ", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return
+	}
 
 	response := struct {
-		Users       *domain.UsersList `json:"users"`
-		CurrentPage int               `json:"currentPage"`
-		PrevPage    int               `json:"previousPage"`
-		NextPage    int               `json:"nextPage"`
+		Users *domain.UserSearchResults `json:"users"`
+		pageMetadata
 	}{
-		Users:       users,
-		CurrentPage: page,
-		PrevPage:    previousPage,
-		NextPage:    nextPage,
+		Users:        users,
+		pageMetadata: newPageMetadata(w, r, page, info),
 	}
 
 	utils.RespondWithJSON(w, status.OK, response)
 }
+
+// maxUserAge bounds the age filters, anything above is a typo
+const maxUserAge = 150
+
+// parseUserFilter reads the filters shared by the user listing and search:
+// blocked, gender, location, registeredFrom and registeredTo (YYYY-MM-DD), minAge, maxAge, hasEmail and hasPhoto
+func parseUserFilter(values url.Values) (domain.UserFilter, error) {
+	var filter domain.UserFilter
+	var err error
+
+	if filter.Blocked, err = parseBoolParam(values, "blocked"); err != nil {
+		return filter, err
+	}
+
+	if filter.HasEmail, err = parseBoolParam(values, "hasEmail"); err != nil {
+		return filter, err
+	}
+
+	if filter.HasPhoto, err = parseBoolParam(values, "hasPhoto"); err != nil {
+		return filter, err
+	}
+
+	filter.Gender = strings.TrimSpace(values.Get("gender"))
+	filter.Location = strings.TrimSpace(values.Get("location"))
+
+	if filter.RegisteredFrom, err = parseDateParam(values, "registeredFrom"); err != nil {
+		return filter, err
+	}
+
+	if filter.RegisteredTo, err = parseDateParam(values, "registeredTo"); err != nil {
+		return filter, err
+	}
+
+	if filter.RegisteredFrom != nil && filter.RegisteredTo != nil && filter.RegisteredTo.Before(*filter.RegisteredFrom) {
+		return filter, fmt.Errorf("registeredTo must not be before registeredFrom")
+	}
+
+	if filter.MinAge, err = parseAgeParam(values, "minAge"); err != nil {
+		return filter, err
+	}
+
+	if filter.MaxAge, err = parseAgeParam(values, "maxAge"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinAge != nil && filter.MaxAge != nil && *filter.MaxAge < *filter.MinAge {
+		return filter, fmt.Errorf("maxAge must not be less than minAge")
+	}
+
+	return filter, nil
+}
+
+func parseBoolParam(values url.Values, name string) (*bool, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", name)
+	}
+
+	return &parsed, nil
+}
+
+func parseDateParam(values url.Values, name string) (*time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date in the YYYY-MM-DD format", name)
+	}
+
+	return &parsed, nil
+}
+
+func parseAgeParam(values url.Values, name string) (*int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 || parsed > maxUserAge {
+		return nil, fmt.Errorf("%s must be a whole number between 0 and %d", name, maxUserAge)
+	}
+
+	return &parsed, nil
+}
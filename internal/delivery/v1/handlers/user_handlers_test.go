@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseUserFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"", false},
+		{"blocked=true&hasEmail=false&hasPhoto=1", false},
+		{"registeredFrom=2024-01-01&registeredTo=2024-01-31", false},
+		{"registeredFrom=2024-01-31&registeredTo=2024-01-31", false},
+		{"registeredFrom=2024-02-01&registeredTo=2024-01-31", true},
+		{"registeredFrom=2024-1-1", true},
+		{"registeredTo=2024-01-31T00:00:00Z", true},
+		{"registeredTo=2024-02-30", true},
+		{"minAge=18&maxAge=18", false},
+		{"minAge=30&maxAge=18", true},
+		{"minAge=-1", true},
+		{"maxAge=151", true},
+		{"minAge=ten", true},
+		{"blocked=maybe", true},
+	}
+
+	for _, test := range tests {
+		values, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := parseUserFilter(values); (err != nil) != test.wantErr {
+			t.Errorf("parseUserFilter(%q) error = %v, want error %v", test.query, err, test.wantErr)
+		}
+	}
+}
+
+func TestParseUserFilterDates(t *testing.T) {
+	values := url.Values{"registeredFrom": {"2024-01-01"}, "registeredTo": {"2024-01-31"}, "gender": {" female "}}
+
+	filter, err := parseUserFilter(values)
+	if err != nil {
+		t.Fatalf("parseUserFilter: %v", err)
+	}
+
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); filter.RegisteredFrom == nil || !filter.RegisteredFrom.Equal(want) {
+		t.Errorf("RegisteredFrom = %v, want %v", filter.RegisteredFrom, want)
+	}
+	if want := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC); filter.RegisteredTo == nil || !filter.RegisteredTo.Equal(want) {
+		t.Errorf("RegisteredTo = %v, want %v", filter.RegisteredTo, want)
+	}
+	if filter.Gender != "female" {
+		t.Errorf("Gender = %q, want it trimmed", filter.Gender)
+	}
+	if filter.Blocked != nil || filter.MinAge != nil {
+		t.Errorf("unset parameters filter: %+v", filter)
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"user-admin/pkg/lib/utils"
+)
+
+// ActivityRecorder remembers when an admin last made an authenticated request
+type ActivityRecorder interface {
+	RecordActivity(adminID int32) error
+}
+
+// TrackActivity records the activity of the admin behind the request, it runs after AuthMiddleware.
+// Requests made with API keys are not attributed to any admin, impersonated ones count for the impersonator.
+func TrackActivity(activity ActivityRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal, ok := GetPrincipal(r.Context()); ok && principal.IsAdmin() {
+				adminID := principal.AdminID
+				if principal.IsImpersonated() {
+					adminID = principal.ImpersonatorID
+				}
+
+				if err := activity.RecordActivity(adminID); err != nil {
+					slog.Error("Error recording admin activity:", utils.Err(err))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+)
+
+// PermissionChecker resolves the permissions granted to a role
+type PermissionChecker interface {
+	HasPermission(role, permission string) (bool, error)
+}
+
+// Authorizer guards routes with the permission they require. Admins are granted
+// permissions through their role, API keys through their scopes.
+type Authorizer struct {
+	permissions PermissionChecker
+}
+
+func NewAuthorizer(permissions PermissionChecker) *Authorizer {
+	return &Authorizer{permissions: permissions}
+}
+
+// Require lets the request through only when the authenticated admin or API key holds the permission
+func (a *Authorizer) Require(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := GetPrincipal(r.Context())
+			if !ok {
+				utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenClaimsNotFound)
+				return
+			}
+
+			if !principal.IsAdmin() {
+				if !principal.APIKey.HasScope(permission) {
+					utils.RespondWithErrorJSON(w, status.Forbidden, errors.InsufficientScope)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, err := a.permissions.HasPermission(principal.Role, permission)
+			if err != nil {
+				slog.Error("Error checking permission:", utils.Err(err))
+				utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+				return
+			}
+
+			if !allowed {
+				utils.RespondWithErrorJSON(w, status.Forbidden, errors.InsufficientPermission)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
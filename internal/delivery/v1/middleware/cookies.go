@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// Cookies of the browser session mode. The access and refresh tokens are HttpOnly, the
+// CSRF token is readable by the admin panel so it can echo it in the CSRFHeader.
+const (
+	AccessTokenCookie  = "admin_access_token"
+	RefreshTokenCookie = "admin_refresh_token"
+	CSRFTokenCookie    = "admin_csrf_token"
+	CSRFHeader         = "X-CSRF-Token"
+)
+
+// ValidCSRFToken implements the double-submit check: the header must repeat the CSRF cookie.
+// Safe methods never change state and pass without it.
+func ValidCSRFToken(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	cookie, err := r.Cookie(CSRFTokenCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	header := r.Header.Get(CSRFHeader)
+
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
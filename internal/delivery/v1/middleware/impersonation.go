@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/errors"
+	"user-admin/pkg/lib/status"
+	"user-admin/pkg/lib/utils"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// ImpersonatorHeader names the impersonator behind an impersonated request in the response
+const ImpersonatorHeader = "X-Impersonated-By"
+
+// SecurityEventRecorder stores security events for later review
+type SecurityEventRecorder interface {
+	CreateSecurityEvent(event *domain.SecurityEvent) error
+}
+
+// AuditImpersonation records every request made with an impersonation token, so that each
+// action is attributed to both the impersonated admin and the impersonator. It runs after AuthMiddleware.
+func AuditImpersonation(events SecurityEventRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := GetPrincipal(r.Context())
+			if !ok || !principal.IsImpersonated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			err := events.CreateSecurityEvent(&domain.SecurityEvent{
+				AdminID:   principal.AdminID,
+				EventType: domain.SecurityEventImpersonatedRequest,
+				IPAddress: utils.ClientIP(r),
+				UserAgent: r.UserAgent(),
+				Details: map[string]interface{}{
+					"impersonator_id": principal.ImpersonatorID,
+					"method":          r.Method,
+					"path":            r.URL.Path,
+					"status":          ww.Status(),
+				},
+			})
+			if err != nil {
+				slog.Error("Error recording impersonated request:", utils.Err(err))
+			}
+		})
+	}
+}
+
+// RejectImpersonation keeps routes that touch the admin's own credentials out of reach of impersonation tokens
+func RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := GetPrincipal(r.Context()); ok && principal.IsImpersonated() {
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.NotAllowedWhileImpersonating)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
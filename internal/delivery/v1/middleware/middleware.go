@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
-	"user-admin/internal/config"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/jwtkeys"
 	"user-admin/pkg/lib/errors"
 	"user-admin/pkg/lib/status"
 	"user-admin/pkg/lib/utils"
@@ -19,82 +22,250 @@ import (
 type contextKey string
 
 const (
-	// tokenKey is the context key for storing the JWT claims in the context.
-	tokenKey contextKey = "token"
+	// principalKey is the context key for storing who the request was authenticated as.
+	principalKey contextKey = "principal"
 )
 
-func AuthMiddleware(cfg *config.Config, allowedRoles []string) func(http.Handler) http.Handler {
+// apiKeyHeader carries the API key of service-to-service requests
+const apiKeyHeader = "X-API-Key"
+
+// TokenRevocationChecker reports whether an access token was revoked after it had been issued,
+// e.g. because its session ended or the admin changed their password
+type TokenRevocationChecker interface {
+	IsAccessTokenRevoked(adminID int32, sessionID string, issuedAt time.Time) (bool, error)
+}
+
+// APIKeyAuthenticator resolves the API key presented by a service
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key, ipAddress string) (*domain.APIKey, error)
+}
+
+// AccessPolicyChecker returns domain.ErrAccessDenied when the access policies of the admin
+// do not allow the client at this time
+type AccessPolicyChecker interface {
+	CheckAccess(adminID int32, client domain.ClientInfo) error
+}
+
+// AuthMiddleware accepts admin access tokens, and API keys as well when apiKeys is set.
+// It only authenticates and applies access policies; every route behind it declares its permission with Authorizer.Require.
+func AuthMiddleware(keySet *jwtkeys.KeySet, revocations TokenRevocationChecker, apiKeys APIKeyAuthenticator, policies AccessPolicyChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			tokenString := extractTokenFromHeader(r)
-			if tokenString == "" {
-				utils.RespondWithErrorJSON(w, status.Unauthorized, errors.AuthorizationTokenNotProvided)
+			if key := r.Header.Get(apiKeyHeader); key != "" {
+				if apiKeys == nil {
+					utils.RespondWithErrorJSON(w, status.Unauthorized, errors.APIKeyNotAccepted)
+					return
+				}
+
+				apiKey, err := apiKeys.AuthenticateAPIKey(key, utils.ClientIP(r))
+				if err != nil {
+					if err != domain.ErrInvalidAPIKey {
+						slog.Error("Error authenticating api key:", utils.Err(err))
+					}
+					utils.RespondWithErrorJSON(w, status.Unauthorized, errors.InvalidAPIKey)
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), principalKey, &domain.Principal{APIKey: apiKey})
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
-			claims, err := validateToken(tokenString, cfg, isRefreshToken(r))
-			if err != nil {
-				utils.RespondWithErrorJSON(w, status.Unauthorized, fmt.Sprintf("Invalid authorization token: %v", err))
+			claims, ok := authenticate(w, r, keySet, revocations)
+			if !ok {
 				return
 			}
 
-			ctx := r.Context()
-			ctx = context.WithValue(ctx, tokenKey, claims)
+			// Restricted tokens of a pending login step are never accepted as access tokens
+			if _, ok := claims["purpose"]; ok {
+				utils.RespondWithErrorJSON(w, status.Unauthorized, errors.RestrictedToken)
+				return
+			}
 
-			if hasRequiredRole(claims["role"].(string), []string{"super_admin"}) {
-				next.ServeHTTP(w, r.WithContext(ctx))
+			if _, ok := claims["role"].(string); !ok {
+				utils.RespondWithErrorJSON(w, status.Unauthorized, errors.RoleNotFoundInTokenClaims)
 				return
 			}
 
-			if !hasRequiredRole(claims["role"].(string), allowedRoles) {
-				utils.RespondWithErrorJSON(w, status.Forbidden, errors.InsufficientPermission)
+			principal := newPrincipal(claims)
+
+			// Policies restrict the person behind the request, which is the impersonator when impersonating
+			adminID := principal.AdminID
+			if principal.IsImpersonated() {
+				adminID = principal.ImpersonatorID
+			}
+
+			if !checkAccessPolicy(w, r, policies, adminID) {
 				return
 			}
 
+			// Every response to an impersonated request names the impersonator behind it
+			if principal.IsImpersonated() {
+				w.Header().Set(ImpersonatorHeader, strconv.Itoa(int(principal.ImpersonatorID)))
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey, principal)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-func validateToken(tokenString string, cfg *config.Config, isRefreshToken bool) (jwt.MapClaims, error) {
-	var secretKey string
+// PurposeMiddleware accepts regular access tokens as well as restricted tokens
+// that were issued for the given purpose, e.g. enrolling into 2FA during login.
+// Access policies apply to these steps as they do to every other request.
+func PurposeMiddleware(keySet *jwtkeys.KeySet, revocations TokenRevocationChecker, policies AccessPolicyChecker, purpose string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := authenticate(w, r, keySet, revocations)
+			if !ok {
+				return
+			}
+
+			if tokenPurpose, ok := claims["purpose"]; ok && tokenPurpose != purpose {
+				utils.RespondWithErrorJSON(w, status.Unauthorized, errors.RestrictedToken)
+				return
+			}
+
+			// Credentials of an admin are never changed by someone impersonating them
+			if _, ok := claims["act"]; ok {
+				utils.RespondWithErrorJSON(w, status.Forbidden, errors.NotAllowedWhileImpersonating)
+				return
+			}
 
-	if isRefreshToken {
-		secretKey = cfg.JWT.RefreshSecretKey
-	} else {
-		secretKey = cfg.JWT.AccessSecretKey
+			principal := newPrincipal(claims)
+			if !checkAccessPolicy(w, r, policies, principal.AdminID) {
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
 	}
+}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// checkAccessPolicy writes the error response when the access policies of the admin do not allow the request
+func checkAccessPolicy(w http.ResponseWriter, r *http.Request, policies AccessPolicyChecker, adminID int32) bool {
+	client := domain.ClientInfo{UserAgent: r.UserAgent(), IPAddress: utils.ClientIP(r)}
+	if err := policies.CheckAccess(adminID, client); err != nil {
+		if err == domain.ErrAccessDenied {
+			utils.RespondWithErrorJSON(w, status.Forbidden, errors.AccessPolicyDenied)
+			return false
 		}
 
-		return []byte(secretKey), nil
-	})
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return false
+	}
 
-	if err != nil || !token.Valid {
-		slog.Error("Token validation error: %v", utils.Err(err))
-		return nil, fmt.Errorf("token validation error: %v", err)
+	return true
+}
+
+// authenticate validates the access token of the request and writes the error response when it is not accepted.
+// The Authorization header takes precedence over the session cookie, only the cookie needs CSRF protection.
+func authenticate(w http.ResponseWriter, r *http.Request, keySet *jwtkeys.KeySet, revocations TokenRevocationChecker) (jwt.MapClaims, bool) {
+	tokenString := extractTokenFromHeader(r)
+	if tokenString == "" {
+		if cookie, err := r.Cookie(AccessTokenCookie); err == nil && cookie.Value != "" {
+			if !ValidCSRFToken(r) {
+				utils.RespondWithErrorJSON(w, status.Forbidden, errors.InvalidCSRFToken)
+				return nil, false
+			}
+
+			tokenString = cookie.Value
+		}
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || claims == nil {
-		slog.Error("Invalid token claims")
-		return nil, fmt.Errorf("invalid token claims")
+	if tokenString == "" {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.AuthorizationTokenNotProvided)
+		return nil, false
 	}
 
-	return claims, nil
+	claims, err := validateToken(tokenString, keySet)
+	if err != nil {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, fmt.Sprintf("Invalid authorization token: %v", err))
+		return nil, false
+	}
+
+	adminID, _ := claims["id"].(float64)
+	sessionID, _ := claims["sid"].(string)
+	issuedAt, _ := claims["iat"].(float64)
+
+	revoked, err := revocations.IsAccessTokenRevoked(int32(adminID), sessionID, time.Unix(int64(issuedAt), 0))
+	if err != nil {
+		slog.Error("Error checking token revocation:", utils.Err(err))
+		utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+		return nil, false
+	}
+
+	// An impersonation token also ends when the tokens of the impersonator behind it are revoked
+	if impersonatorID := actorID(claims); !revoked && impersonatorID != 0 {
+		revoked, err = revocations.IsAccessTokenRevoked(impersonatorID, sessionID, time.Unix(int64(issuedAt), 0))
+		if err != nil {
+			slog.Error("Error checking token revocation:", utils.Err(err))
+			utils.RespondWithErrorJSON(w, status.InternalServerError, errors.InternalServerError)
+			return nil, false
+		}
+	}
+
+	if revoked {
+		utils.RespondWithErrorJSON(w, status.Unauthorized, errors.TokenRevoked)
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// GetPrincipal returns who the request was authenticated as by AuthMiddleware or PurposeMiddleware
+func GetPrincipal(ctx context.Context) (*domain.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*domain.Principal)
+	return principal, ok
+}
+
+func newPrincipal(claims jwt.MapClaims) *domain.Principal {
+	adminID, _ := claims["id"].(float64)
+	role, _ := claims["role"].(string)
+	sessionID, _ := claims["sid"].(string)
+	tokenID, _ := claims["jti"].(string)
+	purpose, _ := claims["purpose"].(string)
+	issuedAt, _ := claims["iat"].(float64)
+	expiresAt, _ := claims["exp"].(float64)
+
+	return &domain.Principal{
+		AdminID:        int32(adminID),
+		Role:           role,
+		SessionID:      sessionID,
+		TokenID:        tokenID,
+		Purpose:        purpose,
+		ImpersonatorID: actorID(claims),
+		IssuedAt:       time.Unix(int64(issuedAt), 0),
+		ExpiresAt:      time.Unix(int64(expiresAt), 0),
+	}
+}
+
+// actorID returns the impersonator named by the act claim of an impersonation token, or 0
+func actorID(claims jwt.MapClaims) int32 {
+	act, ok := claims["act"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+
+	id, _ := act["id"].(float64)
+	return int32(id)
 }
 
-func isRefreshToken(r *http.Request) bool {
-	return strings.Contains(r.URL.Path, "/refresh")
+// validateToken verifies the access token with the key named by its kid header
+func validateToken(tokenString string, keySet *jwtkeys.KeySet) (jwt.MapClaims, error) {
+	claims, err := keySet.Parse(tokenString)
+	if err != nil {
+		slog.Error("Token validation error: %v", utils.Err(err))
+		return nil, fmt.Errorf("token validation error: %v", err)
+	}
+
+	return claims, nil
 }
 
 func extractTokenFromHeader(r *http.Request) string {
 	bearerToken := r.Header.Get("Authorization")
 	if bearerToken == "" {
-		slog.Error("Authorization header not found")
 		return ""
 	}
 
@@ -105,12 +276,3 @@ func extractTokenFromHeader(r *http.Request) string {
 
 	return strings.TrimPrefix(bearerToken, "Bearer ")
 }
-
-func hasRequiredRole(adminRole string, allowedRoles []string) bool {
-	for _, allowedRole := range allowedRoles {
-		if adminRole == allowedRole {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SetupAccessPolicyRoutes(adminRouter *chi.Mux, accessPolicyService *service.AccessPolicyService, authorizer *middleware.Authorizer) {
+	accessPolicyHandler := handlers.AccessPolicyHandler{
+		AccessPolicyService: accessPolicyService,
+	}
+
+	manageAdmins := authorizer.Require(domain.PermissionAdminsManage)
+
+	adminRouter.With(manageAdmins).Get("/access-policies", accessPolicyHandler.GetAccessPoliciesHandler)
+	adminRouter.With(manageAdmins).Post("/access-policies", accessPolicyHandler.CreateAccessPolicyHandler)
+	adminRouter.With(manageAdmins).Put("/access-policies/{id}", accessPolicyHandler.UpdateAccessPolicyHandler)
+	adminRouter.With(manageAdmins).Delete("/access-policies/{id}", accessPolicyHandler.DeleteAccessPolicyHandler)
+}
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetupAccessReviewRoutes mounts the access review campaigns. Admin managers can follow
+// them, deciding and everything else that changes them requires admins:review.
+func SetupAccessReviewRoutes(adminRouter *chi.Mux, accessReviewService *service.AccessReviewService, authorizer *middleware.Authorizer) {
+	accessReviewHandler := handlers.AccessReviewHandler{
+		AccessReviewService: accessReviewService,
+	}
+
+	manageAdmins := authorizer.Require(domain.PermissionAdminsManage)
+	reviewAdmins := authorizer.Require(domain.PermissionAdminsReview)
+
+	adminRouter.With(manageAdmins).Get("/access-reviews", accessReviewHandler.GetAccessReviewsHandler)
+	adminRouter.With(reviewAdmins).Post("/access-reviews", accessReviewHandler.CreateAccessReviewHandler)
+	adminRouter.With(manageAdmins).Get("/access-reviews/{id}", accessReviewHandler.GetAccessReviewHandler)
+	adminRouter.With(manageAdmins).Get("/access-reviews/{id}/export", accessReviewHandler.ExportAccessReviewHandler)
+	adminRouter.With(reviewAdmins).Post("/access-reviews/{id}/close", accessReviewHandler.CloseAccessReviewHandler)
+	adminRouter.With(reviewAdmins).Post("/access-reviews/{id}/admins/{adminID}/confirm", accessReviewHandler.ConfirmAdminHandler)
+	adminRouter.With(reviewAdmins).Post("/access-reviews/{id}/admins/{adminID}/revoke", accessReviewHandler.RevokeAdminHandler)
+}
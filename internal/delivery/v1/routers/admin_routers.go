@@ -2,21 +2,34 @@ package routers
 
 import (
 	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
 	"user-admin/internal/service"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func SetupAdminRoutes(adminRouter *chi.Mux, adminService *service.AdminService) {
+func SetupAdminRoutes(adminRouter *chi.Mux, adminService *service.AdminService, authorizer *middleware.Authorizer) {
 	adminHandler := handlers.AdminHandler{
 		AdminService: adminService,
 		Router:       adminRouter,
 	}
 
-	adminRouter.Get("/", adminHandler.GetAllAdminsHandler)
-	adminRouter.Get("/{id}", adminHandler.GetAdminByID)
-	adminRouter.Post("/", adminHandler.CreateAdminHandler)
-	adminRouter.Put("/{id}", adminHandler.UpdateAdminHandler)
-	adminRouter.Delete("/{id}", adminHandler.DeleteAdminHandler)
-	adminRouter.Get("/search", adminHandler.SearchAdminsHandler)
+	manageAdmins := authorizer.Require(domain.PermissionAdminsManage)
+
+	adminRouter.With(manageAdmins).Get("/", adminHandler.GetAllAdminsHandler)
+	adminRouter.With(manageAdmins).Get("/{id}", adminHandler.GetAdminByID)
+	adminRouter.With(manageAdmins).Post("/", adminHandler.CreateAdminHandler)
+	adminRouter.With(manageAdmins).Put("/{id}", adminHandler.UpdateAdminHandler)
+	adminRouter.With(manageAdmins).Delete("/{id}", adminHandler.DeleteAdminHandler)
+	adminRouter.With(manageAdmins).Get("/search", adminHandler.SearchAdminsHandler)
+	adminRouter.With(manageAdmins).Post("/{id}/unlock", adminHandler.UnlockAdminHandler)
+	adminRouter.With(manageAdmins).Post("/{id}/disable", adminHandler.DisableAdminHandler)
+	adminRouter.With(authorizer.Require(domain.PermissionAdminsEnable)).Post("/{id}/enable", adminHandler.EnableAdminHandler)
+
+	readSecurityEvents := authorizer.Require(domain.PermissionSecurityEventsRead)
+
+	adminRouter.With(readSecurityEvents).Get("/security-events", adminHandler.GetSecurityEventsHandler)
+	adminRouter.With(readSecurityEvents).Get("/logins/failures", adminHandler.GetLoginFailuresHandler)
+	adminRouter.With(readSecurityEvents).Get("/{id}/logins", adminHandler.GetLoginEventsHandler)
 }
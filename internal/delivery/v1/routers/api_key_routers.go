@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SetupAPIKeyRoutes(adminRouter *chi.Mux, apiKeyService *service.APIKeyService, authorizer *middleware.Authorizer) {
+	apiKeyHandler := handlers.APIKeyHandler{
+		APIKeyService: apiKeyService,
+	}
+
+	manageAPIKeys := authorizer.Require(domain.PermissionAPIKeysManage)
+
+	adminRouter.With(manageAPIKeys).Get("/api-keys", apiKeyHandler.GetAPIKeysHandler)
+	adminRouter.With(manageAPIKeys).Post("/api-keys", apiKeyHandler.CreateAPIKeyHandler)
+	adminRouter.With(manageAPIKeys).Delete("/api-keys/{id}", apiKeyHandler.RevokeAPIKeyHandler)
+}
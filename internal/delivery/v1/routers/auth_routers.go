@@ -1,19 +1,66 @@
 package routers
 
 import (
+	"net/http"
+	"user-admin/internal/config"
 	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
 	"user-admin/internal/service"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func SetupAuthRoutes(authRouter *chi.Mux, adminAuthService *service.AdminAuthService) {
+func SetupAuthRoutes(authRouter *chi.Mux, adminAuthService *service.AdminAuthService, adminAuth, mfaEnrollmentAuth, passwordChangeAuth func(http.Handler) http.Handler, authorizer *middleware.Authorizer, cookies config.SessionCookies) {
 	authHandler := handlers.AdminAuthHandler{
 		AdminAuthService: *adminAuthService,
 		Router:           authRouter,
+		Cookies:          cookies,
 	}
 
 	authRouter.Post("/login", authHandler.LoginHandler)
 	authRouter.Post("/refresh", authHandler.RefreshTokensHandler)
 	authRouter.Post("/logout", authHandler.LogoutHandler)
+	authRouter.Post("/mfa/verify", authHandler.VerifyMFAHandler)
+	authRouter.Post("/password/forgot", authHandler.ForgotPasswordHandler)
+	authRouter.Post("/password/reset", authHandler.ResetPasswordHandler)
+
+	// 2FA enrollment, also reachable with the restricted token of a pending login
+	authRouter.Group(func(r chi.Router) {
+		r.Use(mfaEnrollmentAuth)
+		r.Post("/mfa/enroll", authHandler.EnrollMFAHandler)
+		r.Post("/mfa/enroll/confirm", authHandler.ConfirmMFAEnrollmentHandler)
+	})
+
+	// Password change, also reachable with the restricted token of a login that requires one
+	authRouter.Group(func(r chi.Router) {
+		r.Use(passwordChangeAuth)
+		r.Post("/password", authHandler.ChangePasswordHandler)
+	})
+
+	// Sessions and 2FA of the current admin
+	authRouter.Group(func(r chi.Router) {
+		r.Use(adminAuth)
+		r.Get("/sessions", authHandler.GetSessionsHandler)
+		r.With(middleware.RejectImpersonation).Delete("/sessions/{sessionID}", authHandler.RevokeSessionHandler)
+		r.With(middleware.RejectImpersonation).Post("/mfa/disable", authHandler.DisableMFAHandler)
+	})
+
+	// Sessions and 2FA of any admin
+	authRouter.Group(func(r chi.Router) {
+		r.Use(adminAuth, authorizer.Require(domain.PermissionAdminsManage))
+		r.Get("/admins/{id}/sessions", authHandler.GetAdminSessionsHandler)
+		r.Delete("/admins/{id}/sessions/{sessionID}", authHandler.RevokeAdminSessionHandler)
+		r.Delete("/admins/{id}/mfa", authHandler.ResetAdminMFAHandler)
+	})
+
+	// The service never lets an admin impersonate someone whose role outranks theirs
+	authRouter.With(adminAuth, authorizer.Require(domain.PermissionAdminsImpersonate)).Post("/admins/{id}/impersonate", authHandler.ImpersonateAdminHandler)
+
+	// 2FA requirements of roles
+	authRouter.Group(func(r chi.Router) {
+		r.Use(adminAuth, authorizer.Require(domain.PermissionRolesManage))
+		r.Get("/mfa/roles", authHandler.GetRoleMFARequirementsHandler)
+		r.Put("/mfa/roles/{role}", authHandler.SetRoleMFARequirementHandler)
+	})
 }
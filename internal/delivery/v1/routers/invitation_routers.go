@@ -0,0 +1,27 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetupInvitationRoutes serves invitation management to admins and the accept endpoint to invitees,
+// who are not signed in yet
+func SetupInvitationRoutes(adminRouter, authRouter *chi.Mux, invitationService *service.InvitationService, authorizer *middleware.Authorizer) {
+	invitationHandler := handlers.InvitationHandler{
+		InvitationService: invitationService,
+	}
+
+	manageAdmins := authorizer.Require(domain.PermissionAdminsManage)
+
+	adminRouter.With(manageAdmins).Get("/invitations", invitationHandler.GetInvitationsHandler)
+	adminRouter.With(manageAdmins).Post("/invitations", invitationHandler.CreateInvitationHandler)
+	adminRouter.With(manageAdmins).Post("/invitations/{id}/resend", invitationHandler.ResendInvitationHandler)
+	adminRouter.With(manageAdmins).Delete("/invitations/{id}", invitationHandler.RevokeInvitationHandler)
+
+	authRouter.Post("/invitations/{token}/accept", invitationHandler.AcceptInvitationHandler)
+}
@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/pkg/jwtkeys"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SetupJWKSRoutes(router *chi.Mux, keySet *jwtkeys.KeySet) {
+	jwksHandler := handlers.JWKSHandler{KeySet: keySet}
+
+	router.Get("/.well-known/jwks.json", jwksHandler.GetJWKSHandler)
+}
@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SetupOIDCRoutes(authRouter *chi.Mux, oidcService *service.OIDCService) {
+	oidcHandler := handlers.OIDCHandler{
+		OIDCService: oidcService,
+	}
+
+	authRouter.Get("/oidc/login", oidcHandler.LoginHandler)
+	authRouter.Get("/oidc/callback", oidcHandler.CallbackHandler)
+	authRouter.Post("/oidc/callback", oidcHandler.CallbackHandler)
+}
@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"net/http"
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SetupProfileRoutes(authRouter *chi.Mux, profileService *service.ProfileService, adminAuth func(http.Handler) http.Handler) {
+	profileHandler := handlers.ProfileHandler{
+		ProfileService: profileService,
+	}
+
+	authRouter.With(adminAuth).Get("/me", profileHandler.GetProfileHandler)
+}
@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
+	"user-admin/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func SetupRoleRoutes(roleRouter *chi.Mux, roleService *service.RoleService, authorizer *middleware.Authorizer) {
+	roleHandler := handlers.RoleHandler{
+		RoleService: roleService,
+	}
+
+	roleRouter.Use(authorizer.Require(domain.PermissionRolesManage))
+
+	roleRouter.Get("/", roleHandler.GetRolesHandler)
+	roleRouter.Get("/permissions", roleHandler.GetPermissionsHandler)
+	roleRouter.Get("/{name}", roleHandler.GetRoleHandler)
+	roleRouter.Post("/", roleHandler.CreateRoleHandler)
+	roleRouter.Put("/{name}", roleHandler.UpdateRoleHandler)
+	roleRouter.Delete("/{name}", roleHandler.DeleteRoleHandler)
+}
@@ -2,23 +2,28 @@ package routers
 
 import (
 	"user-admin/internal/delivery/v1/handlers"
+	"user-admin/internal/delivery/v1/middleware"
+	"user-admin/internal/domain"
 	"user-admin/internal/service"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func SetupUserRoutes(userRouter *chi.Mux, userService *service.UserService) {
+func SetupUserRoutes(userRouter *chi.Mux, userService *service.UserService, authorizer *middleware.Authorizer) {
 	userHandler := handlers.UserHandler{
 		UserService: userService,
 		Router:      userRouter,
 	}
 
-	userRouter.Get("/", userHandler.GetAllUsersHandler)
-	userRouter.Get("/{id}", userHandler.GetUserByIDHandler)
-	userRouter.Post("/", userHandler.CreateUserHandler)
-	userRouter.Put("/{id}", userHandler.UpdateUserHandler)
-	userRouter.Delete("/{id}", userHandler.DeleteUserHandler)
-	userRouter.Post("/{id}/block", userHandler.BlockUserHandler)
-	userRouter.Post("/{id}/unblock", userHandler.UnblockUserHandler)
-	userRouter.Get("/search", userHandler.SearchUsersHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersRead)).Get("/", userHandler.GetAllUsersHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersDelete)).Get("/trash", userHandler.GetDeletedUsersHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersRead)).Get("/{id}", userHandler.GetUserByIDHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersWrite)).Post("/", userHandler.CreateUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersWrite)).Put("/{id}", userHandler.UpdateUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersDelete)).Delete("/{id}", userHandler.DeleteUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersDelete)).Post("/{id}/restore", userHandler.RestoreUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersPurge)).Delete("/{id}/purge", userHandler.PurgeUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersBlock)).Post("/{id}/block", userHandler.BlockUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersBlock)).Post("/{id}/unblock", userHandler.UnblockUserHandler)
+	userRouter.With(authorizer.Require(domain.PermissionUsersRead)).Get("/search", userHandler.SearchUsersHandler)
 }
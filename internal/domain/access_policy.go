@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// AccessPolicy restricts where from and when the admins of a role, or a single admin, may sign in
+// and use their tokens. Empty CIDRs allow any network, empty weekdays any day, and without hours
+// any time of the day. Hours are a window from StartHour up to EndHour, wrapping past midnight
+// when StartHour is the later one.
+type AccessPolicy struct {
+	ID          int32     `json:"id"`
+	Role        string    `json:"role,omitempty"`
+	AdminID     int32     `json:"admin_id,omitempty"`
+	Description string    `json:"description"`
+	CIDRs       []string  `json:"cidrs"`
+	Weekdays    []int     `json:"weekdays"` // 0 is Sunday
+	StartHour   *int      `json:"start_hour"`
+	EndHour     *int      `json:"end_hour"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AccessPoliciesList struct {
+	Policies []AccessPolicy `json:"policies"`
+}
+
+type AccessPolicyRequest struct {
+	ID          int32    `json:"-"`
+	Role        string   `json:"role"`
+	AdminID     int32    `json:"admin_id"`
+	Description string   `json:"description"`
+	CIDRs       []string `json:"cidrs"`
+	Weekdays    []int    `json:"weekdays"`
+	StartHour   *int     `json:"start_hour"`
+	EndHour     *int     `json:"end_hour"`
+}
+
+var (
+	ErrAccessPolicyNotFound = errors.New("access policy not found")
+	ErrInvalidAccessPolicy  = errors.New("invalid access policy")
+	ErrAccessDenied         = errors.New("access denied by access policy")
+)
+
+// Allows reports whether a request from ip at the given local time satisfies the policy
+func (p *AccessPolicy) Allows(ip net.IP, now time.Time) bool {
+	return p.allowsNetwork(ip) && p.allowsDay(now.Weekday()) && p.allowsHour(now.Hour())
+}
+
+func (p *AccessPolicy) allowsNetwork(ip net.IP) bool {
+	if len(p.CIDRs) == 0 {
+		return true
+	}
+
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range p.CIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *AccessPolicy) allowsDay(day time.Weekday) bool {
+	if len(p.Weekdays) == 0 {
+		return true
+	}
+
+	for _, weekday := range p.Weekdays {
+		if time.Weekday(weekday) == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *AccessPolicy) allowsHour(hour int) bool {
+	if p.StartHour == nil || p.EndHour == nil {
+		return true
+	}
+
+	start, end := *p.StartHour, *p.EndHour
+	if start < end {
+		return hour >= start && hour < end
+	}
+
+	return hour >= start || hour < end
+}
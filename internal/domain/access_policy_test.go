@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func hours(start, end int) (*int, *int) {
+	return &start, &end
+}
+
+// 2024-03-04 is a Monday
+func at(day, hour int) time.Time {
+	return time.Date(2024, 3, 3+day, hour, 30, 0, 0, time.UTC)
+}
+
+func TestAccessPolicyAllowsNetwork(t *testing.T) {
+	policy := AccessPolicy{CIDRs: []string{"10.0.0.0/8", "192.168.1.10/32", "2001:db8::/32"}}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"2001:db8::1", true},
+		{"2001:db8:ffff:ffff::1", true},
+		{"2001:db9::1", false},
+		{"::ffff:10.1.2.3", true},
+		{"::1", false},
+		{"not an ip", false},
+	}
+
+	for _, test := range tests {
+		if got := policy.Allows(net.ParseIP(test.ip), at(1, 12)); got != test.want {
+			t.Errorf("Allows(%s) = %v, want %v", test.ip, got, test.want)
+		}
+	}
+
+	if !(&AccessPolicy{}).Allows(nil, at(1, 12)) {
+		t.Error("a policy without networks refused a client without IP")
+	}
+}
+
+func TestAccessPolicyAllowsTime(t *testing.T) {
+	nineToFive, overnight := AccessPolicy{}, AccessPolicy{}
+	nineToFive.StartHour, nineToFive.EndHour = hours(9, 17)
+	overnight.StartHour, overnight.EndHour = hours(22, 6)
+
+	weekdays := AccessPolicy{Weekdays: []int{1, 2, 3, 4, 5}}
+	weekdays.StartHour, weekdays.EndHour = hours(9, 17)
+
+	tests := []struct {
+		name   string
+		policy AccessPolicy
+		now    time.Time
+		want   bool
+	}{
+		{"any time", AccessPolicy{}, at(0, 3), true},
+		{"start hour included", nineToFive, at(1, 9), true},
+		{"within hours", nineToFive, at(1, 16), true},
+		{"end hour excluded", nineToFive, at(1, 17), false},
+		{"before hours", nineToFive, at(1, 8), false},
+		{"overnight before midnight", overnight, at(1, 23), true},
+		{"overnight after midnight", overnight, at(2, 0), true},
+		{"overnight end hour excluded", overnight, at(2, 6), false},
+		{"overnight during the day", overnight, at(2, 12), false},
+		{"weekday within hours", weekdays, at(5, 10), true},
+		{"saturday", weekdays, at(6, 10), false},
+		{"sunday", weekdays, at(0, 10), false},
+		{"weekday outside hours", weekdays, at(3, 20), false},
+	}
+
+	ip := net.ParseIP("10.1.2.3")
+	for _, test := range tests {
+		if got := test.policy.Allows(ip, test.now); got != test.want {
+			t.Errorf("%s: Allows(%s) = %v, want %v", test.name, test.now.Format(time.RFC1123), got, test.want)
+		}
+	}
+}
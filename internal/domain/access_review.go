@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Decisions a reviewer can take on an admin under review, undecided items are pending
+const (
+	AccessReviewConfirmed = "confirmed"
+	AccessReviewRevoked   = "revoked"
+)
+
+// AccessReview is a campaign recertifying every admin that existed when it was opened
+type AccessReview struct {
+	ID        int32              `json:"id"`
+	Name      string             `json:"name"`
+	CreatedBy int32              `json:"created_by,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	ClosedBy  int32              `json:"closed_by,omitempty"`
+	ClosedAt  *time.Time         `json:"closed_at,omitempty"`
+	Total     int                `json:"total"`
+	Confirmed int                `json:"confirmed"`
+	Revoked   int                `json:"revoked"`
+	Pending   int                `json:"pending"`
+	Items     []AccessReviewItem `json:"items,omitempty"`
+}
+
+// AccessReviewItem is the admin as they were when the review was opened, with the decision taken on them
+type AccessReviewItem struct {
+	AdminID     int32      `json:"admin_id"`
+	Username    string     `json:"username"`
+	Role        string     `json:"role"`
+	Email       string     `json:"email,omitempty"`
+	Disabled    bool       `json:"disabled"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	LastSeenAt  *time.Time `json:"last_seen_at"`
+	Decision    string     `json:"decision,omitempty"`
+	DecidedBy   int32      `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	Comment     string     `json:"comment,omitempty"`
+}
+
+type AccessReviewsList struct {
+	Reviews []AccessReview `json:"reviews"`
+}
+
+type CreateAccessReviewRequest struct {
+	Name string `json:"name"`
+}
+
+type AccessReviewDecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+var (
+	ErrAccessReviewNotFound     = errors.New("access review not found")
+	ErrAccessReviewClosed       = errors.New("access review is closed")
+	ErrAccessReviewItemNotFound = errors.New("admin is not part of the access review")
+	ErrAccessReviewItemDecided  = errors.New("admin has already been reviewed")
+)
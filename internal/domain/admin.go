@@ -10,36 +10,58 @@ type AdminsList struct {
 }
 
 type Admin struct {
-	ID           int32        `json:"id"`
-	Username     string       `json:"username"`
-	Password     string       `json:"password"`
-	Role         string       `json:"role"`
-	RefreshToken RefreshToken `json:"refresh_token"`
-}
-
-type RefreshToken struct {
-	Token          string    `json:"token"`
-	ExpirationTime time.Time `json:"expiration_time"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID                     int32     `json:"id"`
+	Username               string    `json:"username"`
+	Password               string    `json:"password"`
+	Role                   string    `json:"role"`
+	Email                  string    `json:"email"`
+	PasswordChangedAt      time.Time `json:"password_changed_at"`
+	PasswordChangeRequired bool      `json:"password_change_required"`
+	Disabled               bool      `json:"disabled"`
 }
 
 type CreateAdminRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
+	Email    string `json:"email"`
 }
 
 type UpdateAdminRequest struct {
-	ID       int32  `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Role     string `json:"role"`
+	ID                    int32  `json:"id"`
+	Username              string `json:"username"`
+	Password              string `json:"password"`
+	Role                  string `json:"role"`
+	Email                 string `json:"email"`
+	RequirePasswordChange *bool  `json:"require_password_change"`
 }
 
 type CommonAdminResponse struct {
-	ID       int32  `json:"id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	ID             int32      `json:"id"`
+	Username       string     `json:"username"`
+	Role           string     `json:"role"`
+	Email          string     `json:"email,omitempty"`
+	LastLoginAt    *time.Time `json:"last_login_at"`
+	LastSeenAt     *time.Time `json:"last_seen_at"`
+	Disabled       bool       `json:"disabled"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Statuses admins can be filtered by, an empty status matches every admin
+const (
+	AdminStatusActive   = "active"
+	AdminStatusDisabled = "disabled"
+)
+
+// IsAdminStatus reports whether the status filter is known
+func IsAdminStatus(status string) bool {
+	return status == "" || status == AdminStatusActive || status == AdminStatusDisabled
+}
+
+type DisableAdminRequest struct {
+	Reason string `json:"reason"`
 }
 
 var (
@@ -48,4 +70,9 @@ var (
 	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
 	ErrAdminAlreadyExists   = errors.New("admin already exists")
 	ErrAdminCannotBeDeleted = errors.New("super admin cannot be deleted")
+	ErrPasswordReused       = errors.New("password has been used recently")
+	ErrAdminEmailExists     = errors.New("admin with this email already exists")
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrAdminDisabled        = errors.New("admin is disabled")
+	ErrCannotDisableSelf    = errors.New("admins cannot disable themselves")
 )
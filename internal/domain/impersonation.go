@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrImpersonationNotAllowed = errors.New("admins cannot impersonate themselves or impersonate while impersonating")
+)
+
+// Impersonation is a short-lived access token that lets an admin act as another admin.
+// It has no refresh token and ends with the session of the impersonator.
+type Impersonation struct {
+	AccessToken    string              `json:"access_token"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+	Impersonated   bool                `json:"impersonated"`
+	ImpersonatorID int32               `json:"impersonator_id"`
+	Admin          CommonAdminResponse `json:"admin"`
+}
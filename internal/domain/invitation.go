@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusExpired  = "expired"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Invitation is a pending admin, the account is only created once the invitee sets a password
+type Invitation struct {
+	ID         int32      `json:"id"`
+	Username   string     `json:"username"`
+	Email      string     `json:"email,omitempty"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  int32      `json:"invited_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	AdminID    int32      `json:"admin_id,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type InvitationsList struct {
+	Invitations []Invitation `json:"invitations"`
+}
+
+type CreateInvitationRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+// CreatedInvitation is the only time the plain token is ever shown
+type CreatedInvitation struct {
+	Invitation
+	Token string `json:"token"`
+	Link  string `json:"link,omitempty"`
+	// MailSent tells whether the invitation was also mailed to the invitee
+	MailSent bool `json:"mail_sent"`
+}
+
+type AcceptInvitationRequest struct {
+	Password string `json:"password"`
+}
+
+var (
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	ErrInvitationExists       = errors.New("an open invitation already exists for this username")
+	ErrInvitationNotPending   = errors.New("invitation has already been accepted or revoked")
+	ErrInvalidInvitationToken = errors.New("invalid, expired or revoked invitation token")
+)
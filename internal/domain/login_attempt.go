@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoginAttempt counts the failed logins of one username or client IP
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// LoginThrottledError is returned while further login attempts are refused
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account is locked, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+const (
+	LoginResultSuccess     = "success"
+	LoginResultBadPassword = "bad_password"
+	LoginResultLocked      = "locked"
+	LoginResultMFAFailed   = "mfa_failed"
+	LoginResultDenied      = "access_denied"
+	LoginResultDisabled    = "disabled"
+)
+
+const (
+	LoginMethodPassword     = "password"
+	LoginMethodTOTP         = "totp"
+	LoginMethodRecoveryCode = "recovery_code"
+	LoginMethodSSO          = "sso"
+)
+
+// LoginEvent records one attempt to sign in, AdminID is zero when the username is unknown
+type LoginEvent struct {
+	ID        int32     `json:"id"`
+	AdminID   int32     `json:"admin_id,omitempty"`
+	Username  string    `json:"username"`
+	Result    string    `json:"result"`
+	Method    string    `json:"method"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginEventsList struct {
+	Events []LoginEvent `json:"events"`
+}
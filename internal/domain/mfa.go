@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// MFATokenPurpose marks a token that is only good for /auth/mfa/verify
+	MFATokenPurpose = "mfa"
+	// MFAEnrollmentTokenPurpose marks a token that is only good for enrolling into 2FA
+	MFAEnrollmentTokenPurpose = "mfa_enroll"
+	// PasswordChangeTokenPurpose marks a token that is only good for /auth/password
+	PasswordChangeTokenPurpose = "password_change"
+)
+
+type AdminMFA struct {
+	AdminID      int32     `json:"admin_id"`
+	Secret       string    `json:"-"`
+	Enabled      bool      `json:"enabled"`
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type RoleMFARequirement struct {
+	Role     string `json:"role"`
+	Required bool   `json:"required"`
+}
+
+type RoleMFARequirementsList struct {
+	Roles []RoleMFARequirement `json:"roles"`
+}
+
+// LoginResult holds either a token pair or the restricted token for the pending login step
+type LoginResult struct {
+	AccessToken            string
+	RefreshToken           string
+	MFARequired            bool
+	MFAEnrollmentRequired  bool
+	MFAToken               string
+	PasswordChangeRequired bool
+	PasswordChangeToken    string
+}
+
+var (
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFARequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+	ErrInvalidMFAToken   = errors.New("invalid two-factor authentication token")
+)
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// OIDCAuthRequest remembers the secrets of a sign-in that was sent to the identity provider
+type OIDCAuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+var (
+	ErrLocalLoginDisabled      = errors.New("password login is disabled, sign in through single sign-on")
+	ErrInvalidOIDCState        = errors.New("invalid or expired single sign-on state")
+	ErrOIDCIdentityNotFound    = errors.New("single sign-on identity is not linked to an admin")
+	ErrOIDCNoRole              = errors.New("identity provider groups do not grant an admin role")
+	ErrOIDCNotProvisioned      = errors.New("no admin account exists for this identity")
+	ErrOIDCMissingClaim        = errors.New("identity provider did not return a required claim")
+	ErrOIDCProviderUnavailable = errors.New("identity provider request failed")
+)
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidTotalMode = errors.New("invalid total mode")
+)
+
+// How a listing counts its rows when the client asks for the total
+const (
+	TotalExact    = "exact"
+	TotalEstimate = "estimate"
+)
+
+// Cursor points right after the last row of a page. Values are the sort values of that row,
+// one per field of the effective order, which always ends with the ID.
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// PageRequest selects a page either by number or, when Cursor is set, as the rows after the cursor
+type PageRequest struct {
+	Page     int
+	PageSize int
+	Cursor   *Cursor
+	Total    string // empty, TotalExact or TotalEstimate
+}
+
+// PageInfo describes the page a listing returned. NextCursor is set whenever more rows follow,
+// in either mode, so clients can switch from page numbers to cursors at any point.
+type PageInfo struct {
+	HasMore        bool
+	NextCursor     string
+	Total          *int64
+	TotalEstimated bool
+}
+
+// String renders the sort the way clients send it
+func (s Sort) String() string {
+	var value string
+	for i, field := range s {
+		if i > 0 {
+			value += ","
+		}
+		if field.Descending {
+			value += "-"
+		}
+		value += field.Field
+	}
+
+	return value
+}
+
+// EncodeCursor makes the cursor opaque to clients
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads a cursor returned by EncodeCursor. It must have been issued for the same sort.
+func DecodeCursor(value string, sort Sort) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.Sort != sort.String() || len(cursor.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
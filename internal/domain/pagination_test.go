@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort := Sort{{Field: "registration_date", Descending: true}}
+	cursor := Cursor{Sort: sort.String(), Values: []string{"2024-03-01T12:30:00.123456Z", "42"}}
+
+	encoded := EncodeCursor(cursor)
+	if _, err := base64.RawURLEncoding.DecodeString(encoded); err != nil {
+		t.Errorf("cursor %q is not unpadded base64url: %v", encoded, err)
+	}
+
+	decoded, err := DecodeCursor(encoded, sort)
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if !reflect.DeepEqual(*decoded, cursor) {
+		t.Errorf("DecodeCursor = %+v, want %+v", *decoded, cursor)
+	}
+}
+
+func TestDecodeCursorRejectsTampering(t *testing.T) {
+	sort := Sort{{Field: "last_name"}}
+	raw := func(value string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"not base64", "not a cursor!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"last_name","v":["a","1"]}`))},
+		{"not json", raw("last_name,a,1")},
+		{"values of the wrong type", raw(`{"s":"last_name","v":[1,2]}`)},
+		{"no values", raw(`{"s":"last_name","v":[]}`)},
+		{"other sort", EncodeCursor(Cursor{Sort: "-last_name", Values: []string{"a", "1"}})},
+		{"default sort", EncodeCursor(Cursor{Values: []string{"1"}})},
+	}
+
+	for _, test := range tests {
+		if cursor, err := DecodeCursor(test.value, sort); err != ErrInvalidCursor {
+			t.Errorf("%s: DecodeCursor = (%+v, %v), want ErrInvalidCursor", test.name, cursor, err)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+package domain
+
+import "errors"
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
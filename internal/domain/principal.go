@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Principal is whoever a request was authenticated as: an admin holding an
+// access token, or a service holding an API key
+type Principal struct {
+	AdminID   int32
+	Role      string
+	SessionID string
+	TokenID   string
+	// Purpose is set on restricted tokens that only allow finishing a login step
+	Purpose string
+	// ImpersonatorID is the admin acting as this admin through an impersonation token
+	ImpersonatorID int32
+	IssuedAt       time.Time
+	ExpiresAt      time.Time
+	APIKey         *APIKey
+}
+
+// IsAdmin reports whether the request was made by an admin rather than a service
+func (p *Principal) IsAdmin() bool {
+	return p.APIKey == nil
+}
+
+// IsImpersonated reports whether another admin is acting as the admin
+func (p *Principal) IsImpersonated() bool {
+	return p.ImpersonatorID != 0
+}
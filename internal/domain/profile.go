@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// Profile describes the admin an access token belongs to, as seen by that token
+type Profile struct {
+	Admin          CommonAdminResponse `json:"admin"`
+	Permissions    []string            `json:"permissions"`
+	Session        *Session            `json:"session,omitempty"`
+	TokenIssuedAt  time.Time           `json:"token_issued_at"`
+	TokenExpiresAt time.Time           `json:"token_expires_at"`
+	// ImpersonatorID is set when another admin is acting as the admin
+	ImpersonatorID int32 `json:"impersonator_id,omitempty"`
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Permissions a role or an API key can be granted
+const (
+	PermissionUsersRead          = "users:read"
+	PermissionUsersWrite         = "users:write"
+	PermissionUsersBlock         = "users:block"
+	PermissionUsersDelete        = "users:delete"
+	PermissionUsersPurge         = "users:purge"
+	PermissionAdminsManage       = "admins:manage"
+	PermissionAdminsEnable       = "admins:enable"
+	PermissionAdminsReview       = "admins:review"
+	PermissionAdminsImpersonate  = "admins:impersonate"
+	PermissionRolesManage        = "roles:manage"
+	PermissionAPIKeysManage      = "api_keys:manage"
+	PermissionSecurityEventsRead = "security_events:read"
+)
+
+var Permissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersBlock,
+	PermissionUsersDelete,
+	PermissionUsersPurge,
+	PermissionAdminsManage,
+	PermissionAdminsEnable,
+	PermissionAdminsReview,
+	PermissionAdminsImpersonate,
+	PermissionRolesManage,
+	PermissionAPIKeysManage,
+	PermissionSecurityEventsRead,
+}
+
+// SuperAdminRole always holds every permission and cannot be changed, so nobody can lock everyone out
+const SuperAdminRole = "super_admin"
+
+// IsPermission reports whether the permission exists
+func IsPermission(permission string) bool {
+	for _, known := range Permissions {
+		if permission == known {
+			return true
+		}
+	}
+	return false
+}
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	Builtin     bool      `json:"builtin"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RolesList struct {
+	Roles []Role `json:"roles"`
+}
+
+type PermissionsList struct {
+	Permissions []string `json:"permissions"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Name        string   `json:"-"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleAlreadyExists  = errors.New("role already exists")
+	ErrRoleProtected      = errors.New("role cannot be changed")
+	ErrRoleInUse          = errors.New("role is assigned to admins")
+	ErrInvalidRoleName    = errors.New("invalid role name")
+	ErrUnknownPermission  = errors.New("unknown permission")
+	ErrRoleOutranksCaller = errors.New("role holds permissions the caller does not")
+)
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+const (
+	SecurityEventRefreshTokenReuse      = "refresh_token_reuse"
+	SecurityEventMFAReset               = "mfa_reset"
+	SecurityEventLoginLockout           = "login_lockout"
+	SecurityEventLoginUnlock            = "login_unlock"
+	SecurityEventPasswordResetRequested = "password_reset_requested"
+	SecurityEventPasswordReset          = "password_reset"
+	SecurityEventSSOLoginDenied         = "sso_login_denied"
+	SecurityEventSSOProvisioned         = "sso_admin_provisioned"
+	SecurityEventAdminInvited           = "admin_invited"
+	SecurityEventInvitationAccepted     = "invitation_accepted"
+	SecurityEventInvitationRevoked      = "invitation_revoked"
+	SecurityEventImpersonationStarted   = "impersonation_started"
+	SecurityEventImpersonatedRequest    = "impersonated_request"
+	SecurityEventAccessPolicyDenied     = "access_policy_denied"
+	SecurityEventAdminDisabled          = "admin_disabled"
+	SecurityEventAdminEnabled           = "admin_enabled"
+	SecurityEventAccessReviewOpened     = "access_review_opened"
+	SecurityEventAccessReviewClosed     = "access_review_closed"
+	SecurityEventUserPurged             = "user_purged"
+)
+
+// SecurityEvent records a security relevant incident for later review
+type SecurityEvent struct {
+	ID        int32                  `json:"id"`
+	AdminID   int32                  `json:"admin_id,omitempty"`
+	EventType string                 `json:"event_type"`
+	IPAddress string                 `json:"ip_address"`
+	UserAgent string                 `json:"user_agent"`
+	Details   map[string]interface{} `json:"details"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type SecurityEventsList struct {
+	Events []SecurityEvent `json:"events"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type Session struct {
+	ID         string    `json:"id"`
+	AdminID    int32     `json:"admin_id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionsList struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// ClientInfo describes the device a request came from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
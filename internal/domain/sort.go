@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// Sortable fields of the listings, in the form clients name them
+var (
+	UserSortFields  = []string{"id", "first_name", "last_name", "phone_number", "location", "registration_date"}
+	AdminSortFields = []string{"id", "username", "role", "created_at"}
+)
+
+// DeletedUserSort is the fixed order of the trash, most recently deleted first
+var DeletedUserSort = Sort{{Field: "deleted_at", Descending: true}}
+
+// SortField orders a listing by one field, descending when the client prefixed it with "-"
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// Sort orders a listing by its fields in turn. An empty Sort keeps the default order by ID.
+type Sort []SortField
+
+// ParseSort reads a comma separated sort parameter such as "-registration_date,last_name".
+// Every field must be one of allowed and may appear only once.
+func ParseSort(value string, allowed []string) (Sort, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var sort Sort
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		field := SortField{Field: strings.TrimSpace(part)}
+		if strings.HasPrefix(field.Field, "-") {
+			field.Field = field.Field[1:]
+			field.Descending = true
+		}
+
+		if !containsField(allowed, field.Field) || seen[field.Field] {
+			return nil, ErrInvalidSort
+		}
+
+		seen[field.Field] = true
+		sort = append(sort, field)
+	}
+
+	return sort, nil
+}
+
+func containsField(fields []string, field string) bool {
+	for _, name := range fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Sort
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"last_name", Sort{{Field: "last_name"}}, false},
+		{"-registration_date,last_name", Sort{{Field: "registration_date", Descending: true}, {Field: "last_name"}}, false},
+		{" -id , first_name ", Sort{{Field: "id", Descending: true}, {Field: "first_name"}}, false},
+		{"password", nil, true},
+		{"last_name;DROP TABLE users", nil, true},
+		{"COALESCE(first_name, '')", nil, true},
+		{"last_name,-last_name", nil, true},
+		{"--id", nil, true},
+		{"last_name,", nil, true},
+		{"-", nil, true},
+		{"username", nil, true},
+	}
+
+	for _, test := range tests {
+		sort, err := ParseSort(test.value, UserSortFields)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseSort(%q) error = %v, want error %v", test.value, err, test.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(sort, test.want) {
+			t.Errorf("ParseSort(%q) = %+v, want %+v", test.value, sort, test.want)
+		}
+	}
+}
+
+func TestSortString(t *testing.T) {
+	for _, value := range []string{"", "id", "-registration_date,last_name", "role,-created_at"} {
+		sort, err := ParseSort(value, append(append([]string{}, UserSortFields...), AdminSortFields...))
+		if err != nil {
+			t.Fatalf("ParseSort(%q): %v", value, err)
+		}
+		if sort.String() != value {
+			t.Errorf("ParseSort(%q).String() = %q", value, sort.String())
+		}
+	}
+}
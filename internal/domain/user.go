@@ -1,9 +1,15 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUserNotDeleted = errors.New("user is not in the trash")
+)
+
 type Date struct {
 	Day   int32 `json:"day"`
 	Month int32 `json:"month"`
@@ -18,6 +24,20 @@ type UsersList struct {
 	Users []CommonUserResponse `json:"users"`
 }
 
+// UserFilter narrows down user listings and searches. Nil and empty fields do not filter.
+// Ages are in whole years as of today, the registration range includes both days.
+type UserFilter struct {
+	Blocked        *bool
+	Gender         string
+	Location       string
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time
+	MinAge         *int
+	MaxAge         *int
+	HasEmail       *bool
+	HasPhoto       *bool
+}
+
 // CommonUserResponse captures the common properties for GetUserResponse, CreateUserResponse and UpdateUserResponse
 type CommonUserResponse struct {
 	ID               int32     `json:"id"`
@@ -35,6 +55,17 @@ type CommonUserResponse struct {
 
 type GetUserResponse CommonUserResponse
 
+// DeletedUser is a user in the trash. DeletedBy is zero when the admin is gone or an API key deleted the user.
+type DeletedUser struct {
+	CommonUserResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy int32     `json:"deleted_by,omitempty"`
+}
+
+type DeletedUsersList struct {
+	Users []DeletedUser `json:"users"`
+}
+
 type CreateUserRequest struct {
 	FirstName       string `json:"first_name"`
 	LastName        string `json:"last_name"`
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// Fields a search term can be limited to with a qualifier, e.g. email:foo or name:"Ak Myrat".
+// Unqualified terms match names, email and location, and phone numbers when they contain digits.
+var UserSearchFields = []string{"name", "first_name", "last_name", "email", "phone", "location"}
+
+// UserSearchSortFields adds relevance, the default order of searches, to the user sort fields
+var UserSearchSortFields = append(append([]string{}, UserSortFields...), "relevance")
+
+// SearchByRelevance orders search results with the best matches first
+var SearchByRelevance = Sort{{Field: "relevance", Descending: true}}
+
+// SearchTerm is one word or quoted phrase of a search. Field is empty for unqualified terms.
+type SearchTerm struct {
+	Field string
+	Value string
+}
+
+// UserSearchQuery is a parsed search. Every term has to match for a user to be found.
+type UserSearchQuery struct {
+	Terms []SearchTerm
+}
+
+// UserSearchResult is a user found by a search. Rank grows with the relevance of the match,
+// Highlights holds the matching fields with their matches wrapped in <mark> tags and the rest HTML-escaped.
+type UserSearchResult struct {
+	CommonUserResponse
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+type UserSearchResults struct {
+	Users []UserSearchResult `json:"users"`
+}
+
+// ParseUserSearchQuery splits a search into its terms. Quotes keep phrases together, and a search
+// made of nothing but a phone number, e.g. "+993 65 12-34-56", becomes a single phone term.
+func ParseUserSearchQuery(query string) (UserSearchQuery, error) {
+	var search UserSearchQuery
+	var free []string
+	phoneLike := true
+
+	for _, token := range splitSearchQuery(query) {
+		field, value, qualified := cutQualifier(token)
+		value = strings.TrimSpace(strings.ReplaceAll(value, `"`, ""))
+
+		if qualified {
+			if value == "" {
+				return search, ErrInvalidSearchQuery
+			}
+			if field == "phone" && Digits(value) == "" {
+				return search, ErrInvalidSearchQuery
+			}
+
+			search.Terms = append(search.Terms, SearchTerm{Field: field, Value: value})
+			continue
+		}
+
+		if value == "" {
+			continue
+		}
+
+		free = append(free, value)
+		phoneLike = phoneLike && isPhoneLike(value)
+	}
+
+	if phoneLike && len(free) > 0 && Digits(strings.Join(free, "")) != "" {
+		search.Terms = append(search.Terms, SearchTerm{Field: "phone", Value: strings.Join(free, " ")})
+	} else {
+		for _, value := range free {
+			search.Terms = append(search.Terms, SearchTerm{Value: value})
+		}
+	}
+
+	if len(search.Terms) == 0 {
+		return search, ErrInvalidSearchQuery
+	}
+
+	return search, nil
+}
+
+// Digits keeps only the digits of a phone number or search term
+func Digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// splitSearchQuery splits on whitespace outside of quotes, the quotes stay in the tokens
+func splitSearchQuery(query string) []string {
+	var tokens []string
+	var token strings.Builder
+	quoted := false
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			token.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteRune(r)
+		}
+	}
+
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+
+	return tokens
+}
+
+// cutQualifier splits field:value. Only search fields before the first colon qualify, so anything else
+// with a colon, e.g. http://example.com or a quoted phrase, stays a plain term.
+func cutQualifier(token string) (string, string, bool) {
+	field, value, found := strings.Cut(token, ":")
+	if !found || !containsField(UserSearchFields, field) {
+		return "", token, false
+	}
+
+	return field, value, true
+}
+
+// isPhoneLike reports whether the term could be part of a formatted phone number
+func isPhoneLike(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && !strings.ContainsRune("+-().", r) {
+			return false
+		}
+	}
+
+	return true
+}
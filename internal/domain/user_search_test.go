@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUserSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []SearchTerm
+		wantErr bool
+	}{
+		{query: "ak myrat", want: []SearchTerm{{Value: "ak"}, {Value: "myrat"}}},
+		{query: `"Ak Myrat" ashgabat`, want: []SearchTerm{{Value: "Ak Myrat"}, {Value: "ashgabat"}}},
+		{query: `name:"Ak Myrat" email:foo`, want: []SearchTerm{{Field: "name", Value: "Ak Myrat"}, {Field: "email", Value: "foo"}}},
+		{query: "location:Mary first_name:Ak", want: []SearchTerm{{Field: "location", Value: "Mary"}, {Field: "first_name", Value: "Ak"}}},
+		{query: "+993 65 12-34-56", want: []SearchTerm{{Field: "phone", Value: "+993 65 12-34-56"}}},
+		{query: "(65) 123456", want: []SearchTerm{{Field: "phone", Value: "(65) 123456"}}},
+		{query: "phone:+99365", want: []SearchTerm{{Field: "phone", Value: "+99365"}}},
+		{query: "myrat 65", want: []SearchTerm{{Value: "myrat"}, {Value: "65"}}},
+		// Only known fields qualify, anything else with a colon is a plain term
+		{query: "http://example.com", want: []SearchTerm{{Value: "http://example.com"}}},
+		{query: "password:secret", want: []SearchTerm{{Value: "password:secret"}}},
+		{query: `"note: vip"`, want: []SearchTerm{{Value: "note: vip"}}},
+		{query: "12:30", want: []SearchTerm{{Value: "12:30"}}},
+		{query: `"" ak`, want: []SearchTerm{{Value: "ak"}}},
+		{query: "", wantErr: true},
+		{query: `   ""  `, wantErr: true},
+		{query: "email:", wantErr: true},
+		{query: `name:""`, wantErr: true},
+		{query: "phone:abc", wantErr: true},
+		{query: "- ()", want: []SearchTerm{{Value: "-"}, {Value: "()"}}},
+	}
+
+	for _, test := range tests {
+		search, err := ParseUserSearchQuery(test.query)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseUserSearchQuery(%q) error = %v, want error %v", test.query, err, test.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(search.Terms, test.want) {
+			t.Errorf("ParseUserSearchQuery(%q) = %+v, want %+v", test.query, search.Terms, test.want)
+		}
+	}
+}
+
+func TestDigits(t *testing.T) {
+	tests := map[string]string{
+		"+993 (65) 12-34-56": "99365123456",
+		"abc":                "",
+		"٣٤":                 "",
+		"":                   "",
+	}
+
+	for value, want := range tests {
+		if got := Digits(value); got != want {
+			t.Errorf("Digits(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type AccessPolicyRepository interface {
+	GetAccessPolicies() (*domain.AccessPoliciesList, error)
+	GetAccessPoliciesForAdmin(adminID int32) ([]domain.AccessPolicy, error)
+	CreateAccessPolicy(request *domain.AccessPolicyRequest) (*domain.AccessPolicy, error)
+	UpdateAccessPolicy(request *domain.AccessPolicyRequest) (*domain.AccessPolicy, error)
+	DeleteAccessPolicy(id int32) error
+}
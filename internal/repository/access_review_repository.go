@@ -0,0 +1,11 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type AccessReviewRepository interface {
+	CreateAccessReview(name string, createdBy int32) (*domain.AccessReview, error)
+	GetAccessReviews() (*domain.AccessReviewsList, error)
+	GetAccessReview(id int32) (*domain.AccessReview, error)
+	DecideAccessReviewItem(reviewID, adminID, decidedBy int32, decision, comment string) error
+	CloseAccessReview(id, closedBy int32) error
+}
@@ -1,12 +1,20 @@
 package repository
 
-import "user-admin/internal/domain"
+import (
+	"time"
+	"user-admin/internal/domain"
+)
 
 type AdminRepository interface {
-	GetAllAdmins(page, pageSize int) (*domain.AdminsList, error)
+	GetAllAdmins(status string, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error)
 	GetAdminByID(id int32) (*domain.CommonAdminResponse, error)
 	CreateAdmin(request *domain.CreateAdminRequest) (*domain.CommonAdminResponse, error)
 	UpdateAdmin(request *domain.UpdateAdminRequest) (*domain.CommonAdminResponse, error)
 	DeleteAdmin(id int32) error
-	SearchAdmins(query string, page, pageSize int) (*domain.AdminsList, error)
+	SearchAdmins(query, status string, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error)
+	InvalidateAccessTokens(id int32) error
+	RevokeAllSessions(id int32) error
+	DisableAdmin(id, disabledBy int32, reason string) error
+	EnableAdmin(id int32) error
+	DisableDormantAdmins(since time.Time, reason string) ([]int32, error)
 }
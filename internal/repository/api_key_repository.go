@@ -0,0 +1,11 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type APIKeyRepository interface {
+	CreateAPIKey(apiKey *domain.APIKey, key string) (*domain.APIKey, error)
+	GetActiveAPIKey(key string) (*domain.APIKey, error)
+	TouchAPIKey(id int32, ipAddress string) error
+	GetAPIKeys() (*domain.APIKeysList, error)
+	RevokeAPIKey(id int32) error
+}
@@ -1,13 +1,26 @@
 package repository
 
 import (
+	"time"
 	"user-admin/internal/domain"
 )
 
 type AdminAuthRepository interface {
 	GetAdminByUsername(username string) (*domain.Admin, error)
-	GenerateTokenPair(admin *domain.Admin) (string, string, error)
+	GetAdminByEmail(email string) (*domain.Admin, error)
+	GenerateTokenPair(admin *domain.Admin, sessionID string) (string, string, error)
 	ValidateRefreshToken(refreshToken string) (map[string]interface{}, error)
 	GetAdminByID(adminID int) (*domain.Admin, error)
+	MarkRefreshTokenUsed(refreshToken string) error
 	DeleteRefreshToken(refreshToken string) error
+	CreateSession(adminID int32, client domain.ClientInfo) (*domain.Session, error)
+	GetSessionsByAdminID(adminID int32) ([]domain.Session, error)
+	RevokeSession(adminID int32, sessionID string) error
+	GenerateRestrictedToken(admin *domain.Admin, purpose string) (string, error)
+	GenerateImpersonationToken(admin *domain.Admin, impersonatorID int32, sessionID string) (string, time.Time, error)
+	ValidateMFAToken(mfaToken, purpose string) (map[string]interface{}, error)
+	IsAccessTokenRevoked(adminID int32, sessionID string, issuedAt time.Time) (bool, error)
+	UpdatePassword(adminID int32, password string) error
+	RehashPassword(adminID int32, oldHash, password string) error
+	RecordActivity(adminID int32) error
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"time"
+	"user-admin/internal/domain"
+)
+
+type InvitationRepository interface {
+	CreateInvitation(invitation *domain.Invitation, token string) (*domain.Invitation, error)
+	GetInvitations() (*domain.InvitationsList, error)
+	GetPendingInvitation(token string) (*domain.Invitation, error)
+	RenewInvitation(id int32, token string, expiresAt time.Time) (*domain.Invitation, error)
+	RevokeInvitation(id int32) (*domain.Invitation, error)
+	AcceptInvitation(token, password string) (*domain.CommonAdminResponse, error)
+}
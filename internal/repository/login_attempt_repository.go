@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"time"
+	"user-admin/internal/domain"
+)
+
+type LoginAttemptRepository interface {
+	GetLoginAttempt(key string) (*domain.LoginAttempt, error)
+	RegisterFailedLogin(key string, window time.Duration) (*domain.LoginAttempt, error)
+	LockLogin(key string, until time.Time) error
+	ResetLoginAttempts(key string) error
+}
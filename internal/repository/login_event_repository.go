@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"time"
+	"user-admin/internal/domain"
+)
+
+type LoginEventRepository interface {
+	CreateLoginEvent(event *domain.LoginEvent) error
+	GetLoginEvents(adminID int32, page, pageSize int) (*domain.LoginEventsList, error)
+	GetLoginFailures(since time.Time, page, pageSize int) (*domain.LoginEventsList, error)
+}
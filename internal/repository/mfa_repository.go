@@ -0,0 +1,15 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type MFARepository interface {
+	GetMFA(adminID int32) (*domain.AdminMFA, error)
+	SaveMFASecret(adminID int32, secret string) error
+	EnableMFA(adminID int32, recoveryCodes []string) error
+	DeleteMFA(adminID int32) error
+	UseMFAStep(adminID int32, step int64) error
+	UseRecoveryCode(adminID int32, code string) error
+	IsMFARequiredForRole(role string) (bool, error)
+	GetRoleMFARequirements() ([]domain.RoleMFARequirement, error)
+	SetRoleMFARequirement(requirement *domain.RoleMFARequirement) error
+}
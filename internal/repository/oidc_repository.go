@@ -0,0 +1,10 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type OIDCRepository interface {
+	CreateAuthRequest(request *domain.OIDCAuthRequest) error
+	ConsumeAuthRequest(state string) (*domain.OIDCAuthRequest, error)
+	GetIdentityAdminID(issuer, subject string) (int32, error)
+	LinkIdentity(adminID int32, issuer, subject string) error
+}
@@ -0,0 +1,5 @@
+package repository
+
+type PasswordHistoryRepository interface {
+	GetPasswordHistory(adminID int32, limit int) ([]string, error)
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "time"
+
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(adminID int32, token string, expiresAt time.Time) error
+	GetPasswordResetAdminID(token string) (int32, error)
+	UsePasswordResetToken(token string) error
+}
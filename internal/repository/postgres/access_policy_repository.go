@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/lib/pq"
+)
+
+type PostgresAccessPolicyRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresAccessPolicyRepository(db *sql.DB) *PostgresAccessPolicyRepository {
+	return &PostgresAccessPolicyRepository{DB: db}
+}
+
+const accessPolicyColumns = `
+    id, COALESCE(role, ''), COALESCE(admin_id, 0), description, cidrs::text[], weekdays,
+    start_hour, end_hour, created_at`
+
+func scanAccessPolicy(row rowScanner) (*domain.AccessPolicy, error) {
+	var policy domain.AccessPolicy
+	var weekdays pq.Int64Array
+	var startHour, endHour sql.NullInt32
+
+	err := row.Scan(&policy.ID, &policy.Role, &policy.AdminID, &policy.Description, pq.Array(&policy.CIDRs),
+		&weekdays, &startHour, &endHour, &policy.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.CIDRs == nil {
+		policy.CIDRs = make([]string, 0)
+	}
+
+	policy.Weekdays = make([]int, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		policy.Weekdays = append(policy.Weekdays, int(weekday))
+	}
+
+	if startHour.Valid && endHour.Valid {
+		start, end := int(startHour.Int32), int(endHour.Int32)
+		policy.StartHour, policy.EndHour = &start, &end
+	}
+
+	return &policy, nil
+}
+
+func (r *PostgresAccessPolicyRepository) GetAccessPolicies() (*domain.AccessPoliciesList, error) {
+	return r.queryAccessPolicies(`SELECT ` + accessPolicyColumns + ` FROM access_policies ORDER BY id`)
+}
+
+// GetAccessPoliciesForAdmin returns the policies that apply to the admin. Policies of the admin
+// replace those of their role, so one admin can be given more or less room than the rest.
+func (r *PostgresAccessPolicyRepository) GetAccessPoliciesForAdmin(adminID int32) ([]domain.AccessPolicy, error) {
+	query := `
+        SELECT ` + accessPolicyColumns + `
+        FROM access_policies
+        WHERE admin_id = $1
+            OR (
+                role = (SELECT role FROM admins WHERE id = $1)
+                AND NOT EXISTS(SELECT 1 FROM access_policies WHERE admin_id = $1)
+            )
+        ORDER BY id
+    `
+
+	list, err := r.queryAccessPolicies(query, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Policies, nil
+}
+
+func (r *PostgresAccessPolicyRepository) CreateAccessPolicy(request *domain.AccessPolicyRequest) (*domain.AccessPolicy, error) {
+	policy, err := scanAccessPolicy(r.DB.QueryRow(`
+		INSERT INTO access_policies (role, admin_id, description, cidrs, weekdays, start_hour, end_hour)
+		VALUES (NULLIF($1, ''), NULLIF($2, 0), $3, $4::cidr[], $5, $6, $7)
+		RETURNING `+accessPolicyColumns,
+		request.Role, request.AdminID, request.Description, pq.Array(request.CIDRs),
+		weekdaysArray(request.Weekdays), request.StartHour, request.EndHour))
+	if err != nil {
+		slog.Error("Error creating access policy: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *PostgresAccessPolicyRepository) UpdateAccessPolicy(request *domain.AccessPolicyRequest) (*domain.AccessPolicy, error) {
+	policy, err := scanAccessPolicy(r.DB.QueryRow(`
+		UPDATE access_policies
+		SET role = NULLIF($2, ''), admin_id = NULLIF($3, 0), description = $4, cidrs = $5::cidr[],
+		    weekdays = $6, start_hour = $7, end_hour = $8
+		WHERE id = $1
+		RETURNING `+accessPolicyColumns,
+		request.ID, request.Role, request.AdminID, request.Description, pq.Array(request.CIDRs),
+		weekdaysArray(request.Weekdays), request.StartHour, request.EndHour))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAccessPolicyNotFound
+		}
+
+		slog.Error("Error updating access policy: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *PostgresAccessPolicyRepository) DeleteAccessPolicy(id int32) error {
+	result, err := r.DB.Exec(`DELETE FROM access_policies WHERE id = $1`, id)
+	if err != nil {
+		slog.Error("Error deleting access policy: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrAccessPolicyNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresAccessPolicyRepository) queryAccessPolicies(query string, args ...interface{}) (*domain.AccessPoliciesList, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		slog.Error("Error getting access policies: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := domain.AccessPoliciesList{Policies: make([]domain.AccessPolicy, 0)}
+	for rows.Next() {
+		policy, err := scanAccessPolicy(rows)
+		if err != nil {
+			slog.Error("Error scanning access policy row: %v", utils.Err(err))
+			return nil, err
+		}
+		list.Policies = append(list.Policies, *policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over access policy rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func weekdaysArray(weekdays []int) pq.Int64Array {
+	array := make(pq.Int64Array, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		array = append(array, int64(weekday))
+	}
+	return array
+}
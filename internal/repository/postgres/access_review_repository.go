@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresAccessReviewRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresAccessReviewRepository(db *sql.DB) *PostgresAccessReviewRepository {
+	return &PostgresAccessReviewRepository{DB: db}
+}
+
+const accessReviewQuery = `
+        SELECT r.id, r.name, COALESCE(r.created_by, 0), r.created_at, COALESCE(r.closed_by, 0), r.closed_at,
+            COUNT(i.admin_id),
+            COUNT(i.admin_id) FILTER (WHERE i.decision = 'confirmed'),
+            COUNT(i.admin_id) FILTER (WHERE i.decision = 'revoked'),
+            COUNT(i.admin_id) FILTER (WHERE i.decision IS NULL)
+        FROM access_reviews r
+        LEFT JOIN access_review_items i ON i.review_id = r.id
+    `
+
+func scanAccessReview(row rowScanner) (*domain.AccessReview, error) {
+	var review domain.AccessReview
+	var closedAt sql.NullTime
+
+	err := row.Scan(&review.ID, &review.Name, &review.CreatedBy, &review.CreatedAt, &review.ClosedBy, &closedAt,
+		&review.Total, &review.Confirmed, &review.Revoked, &review.Pending)
+	if err != nil {
+		return nil, err
+	}
+
+	if closedAt.Valid {
+		review.ClosedAt = &closedAt.Time
+	}
+
+	return &review, nil
+}
+
+const accessReviewItemColumns = `
+    admin_id, username, role, COALESCE(email, ''), disabled, last_login_at, last_seen_at,
+    COALESCE(decision, ''), COALESCE(decided_by, 0), decided_at, comment`
+
+func scanAccessReviewItem(row rowScanner) (*domain.AccessReviewItem, error) {
+	var item domain.AccessReviewItem
+	var lastLoginAt, lastSeenAt, decidedAt sql.NullTime
+
+	err := row.Scan(&item.AdminID, &item.Username, &item.Role, &item.Email, &item.Disabled, &lastLoginAt, &lastSeenAt,
+		&item.Decision, &item.DecidedBy, &decidedAt, &item.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastLoginAt.Valid {
+		item.LastLoginAt = &lastLoginAt.Time
+	}
+	if lastSeenAt.Valid {
+		item.LastSeenAt = &lastSeenAt.Time
+	}
+	if decidedAt.Valid {
+		item.DecidedAt = &decidedAt.Time
+	}
+
+	return &item, nil
+}
+
+// CreateAccessReview opens a review with a snapshot of every admin
+func (r *PostgresAccessReviewRepository) CreateAccessReview(name string, createdBy int32) (*domain.AccessReview, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int32
+	err = tx.QueryRow(`
+		INSERT INTO access_reviews (name, created_by)
+		VALUES ($1, NULLIF($2, 0))
+		RETURNING id
+	`, name, createdBy).Scan(&id)
+	if err != nil {
+		slog.Error("Error creating access review: %v", utils.Err(err))
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO access_review_items (review_id, admin_id, username, role, email, disabled, last_login_at, last_seen_at)
+		SELECT $1, id, username, role, email, disabled, last_login_at, last_seen_at
+		FROM admins
+	`, id)
+	if err != nil {
+		slog.Error("Error creating access review items: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return r.GetAccessReview(id)
+}
+
+func (r *PostgresAccessReviewRepository) GetAccessReviews() (*domain.AccessReviewsList, error) {
+	rows, err := r.DB.Query(accessReviewQuery + ` GROUP BY r.id ORDER BY r.created_at DESC, r.id DESC`)
+	if err != nil {
+		slog.Error("Error getting access reviews: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := domain.AccessReviewsList{Reviews: make([]domain.AccessReview, 0)}
+	for rows.Next() {
+		review, err := scanAccessReview(rows)
+		if err != nil {
+			slog.Error("Error scanning access review row: %v", utils.Err(err))
+			return nil, err
+		}
+		list.Reviews = append(list.Reviews, *review)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over access review rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// GetAccessReview returns the review with every admin under review
+func (r *PostgresAccessReviewRepository) GetAccessReview(id int32) (*domain.AccessReview, error) {
+	review, err := scanAccessReview(r.DB.QueryRow(accessReviewQuery+` WHERE r.id = $1 GROUP BY r.id`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAccessReviewNotFound
+		}
+
+		slog.Error("Error getting access review: %v", utils.Err(err))
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT `+accessReviewItemColumns+`
+		FROM access_review_items
+		WHERE review_id = $1
+		ORDER BY admin_id
+	`, id)
+	if err != nil {
+		slog.Error("Error getting access review items: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	review.Items = make([]domain.AccessReviewItem, 0)
+	for rows.Next() {
+		item, err := scanAccessReviewItem(rows)
+		if err != nil {
+			slog.Error("Error scanning access review item row: %v", utils.Err(err))
+			return nil, err
+		}
+		review.Items = append(review.Items, *item)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over access review item rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// DecideAccessReviewItem records the decision on a pending admin of an open review, decisions are final
+func (r *PostgresAccessReviewRepository) DecideAccessReviewItem(reviewID, adminID, decidedBy int32, decision, comment string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	var closed bool
+	err = tx.QueryRow(`SELECT closed_at IS NOT NULL FROM access_reviews WHERE id = $1 FOR SHARE`, reviewID).Scan(&closed)
+	if err == sql.ErrNoRows {
+		return domain.ErrAccessReviewNotFound
+	}
+	if err != nil {
+		slog.Error("Error locking access review: %v", utils.Err(err))
+		return err
+	}
+
+	if closed {
+		return domain.ErrAccessReviewClosed
+	}
+
+	result, err := tx.Exec(`
+		UPDATE access_review_items
+		SET decision = $3, decided_by = NULLIF($4, 0), decided_at = CURRENT_TIMESTAMP, comment = $5
+		WHERE review_id = $1 AND admin_id = $2 AND decision IS NULL
+	`, reviewID, adminID, decision, decidedBy, comment)
+	if err != nil {
+		slog.Error("Error deciding access review item: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		var exists bool
+		err := tx.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM access_review_items WHERE review_id = $1 AND admin_id = $2)
+		`, reviewID, adminID).Scan(&exists)
+		if err != nil {
+			slog.Error("Error checking access review item: %v", utils.Err(err))
+			return err
+		}
+
+		if exists {
+			return domain.ErrAccessReviewItemDecided
+		}
+		return domain.ErrAccessReviewItemNotFound
+	}
+
+	return tx.Commit()
+}
+
+// CloseAccessReview ends the review, admins still pending stay undecided in the evidence
+func (r *PostgresAccessReviewRepository) CloseAccessReview(id, closedBy int32) error {
+	result, err := r.DB.Exec(`
+		UPDATE access_reviews
+		SET closed_at = CURRENT_TIMESTAMP, closed_by = NULLIF($2, 0)
+		WHERE id = $1 AND closed_at IS NULL
+	`, id, closedBy)
+	if err != nil {
+		slog.Error("Error closing access review: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		var exists bool
+		if err := r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM access_reviews WHERE id = $1)`, id).Scan(&exists); err != nil {
+			slog.Error("Error checking access review existence: %v", utils.Err(err))
+			return err
+		}
+
+		if exists {
+			return domain.ErrAccessReviewClosed
+		}
+		return domain.ErrAccessReviewNotFound
+	}
+
+	return nil
+}
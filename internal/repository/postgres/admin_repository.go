@@ -7,65 +7,132 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 	"user-admin/internal/domain"
 	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/password"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/lib/pq"
 )
 
 type PostgresAdminRepository struct {
-	DB *sql.DB
+	DB     *sql.DB
+	Hasher *password.Hasher
 }
 
-func NewPostgresAdminRepository(db *sql.DB) *PostgresAdminRepository {
-	return &PostgresAdminRepository{DB: db}
+func NewPostgresAdminRepository(db *sql.DB, hasher *password.Hasher) *PostgresAdminRepository {
+	return &PostgresAdminRepository{DB: db, Hasher: hasher}
 }
 
-func (r *PostgresAdminRepository) GetAllAdmins(page, pageSize int) (*domain.AdminsList, error) {
-	offset := (page - 1) * pageSize
+const adminResponseColumns = `id, username, role, COALESCE(email, ''), last_login_at, last_seen_at,
+    disabled, disabled_at, COALESCE(disabled_reason, ''), created_at`
+
+func scanAdminResponse(row rowScanner) (*domain.CommonAdminResponse, error) {
+	var admin domain.CommonAdminResponse
+	var lastLoginAt, lastSeenAt, disabledAt sql.NullTime
+
+	err := row.Scan(
+		&admin.ID,
+		&admin.Username,
+		&admin.Role,
+		&admin.Email,
+		&lastLoginAt,
+		&lastSeenAt,
+		&admin.Disabled,
+		&disabledAt,
+		&admin.DisabledReason,
+		&admin.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastLoginAt.Valid {
+		admin.LastLoginAt = &lastLoginAt.Time
+	}
+	if lastSeenAt.Valid {
+		admin.LastSeenAt = &lastSeenAt.Time
+	}
+	if disabledAt.Valid {
+		admin.DisabledAt = &disabledAt.Time
+	}
+
+	return &admin, nil
+}
+
+// adminStatusCondition matches every admin for an empty status, the status parameter is given by number
+func adminStatusCondition(param int) string {
+	p := "$" + strconv.Itoa(param)
+	return "(" + p + " = '' OR disabled = (" + p + " = '" + domain.AdminStatusDisabled + "'))"
+}
+
+func (r *PostgresAdminRepository) GetAllAdmins(status string, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error) {
+	return r.listAdmins([]string{adminStatusCondition(1)}, []interface{}{status}, sort, page)
+}
+
+// listAdmins returns a page of the admins matching the conditions, which refer to queryParams by position
+func (r *PostgresAdminRepository) listAdmins(conditions []string, queryParams []interface{}, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error) {
+	info, err := countRows(r.DB, "admins", conditions, queryParams, page.Total)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms := sortTerms(sort, adminSortColumns)
+
+	clause, queryParams, err := pageClause(conditions, queryParams, terms, page)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	query := `
-        SELECT id, username, role
-        FROM admins
-        ORDER BY id
-        LIMIT $1 OFFSET $2
-    `
+        SELECT ` + adminResponseColumns + `
+        FROM admins` + clause
 
 	stmt, err := r.DB.Prepare(query)
 	if err != nil {
 		slog.Error("Error preparing query: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.TODO(), pageSize, offset)
+	rows, err := stmt.QueryContext(context.TODO(), queryParams...)
 	if err != nil {
 		slog.Error("Error executing query: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	adminList := domain.AdminsList{Admins: make([]domain.CommonAdminResponse, 0)}
 	for rows.Next() {
-		var admin domain.CommonAdminResponse
-		if err := rows.Scan(&admin.ID, &admin.Username, &admin.Role); err != nil {
+		admin, err := scanAdminResponse(rows)
+		if err != nil {
 			slog.Error("Error scanning admin row: %v", utils.Err(err))
-			return nil, err
+			return nil, nil, err
 		}
-		adminList.Admins = append(adminList.Admins, admin)
+		adminList.Admins = append(adminList.Admins, *admin)
 	}
 
 	if err := rows.Err(); err != nil {
 		slog.Error("Error iterating over admin rows: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &adminList, nil
+	if len(adminList.Admins) > page.PageSize {
+		adminList.Admins = adminList.Admins[:page.PageSize]
+		last := adminList.Admins[page.PageSize-1]
+
+		info.HasMore = true
+		info.NextCursor = nextCursor(sort, terms, func(field string) string {
+			return adminSortValue(last, field)
+		})
+	}
+
+	return &adminList, info, nil
 }
 
 func (r *PostgresAdminRepository) GetAdminByID(id int32) (*domain.CommonAdminResponse, error) {
 	stmt, err := r.DB.Prepare(`
-		SELECT id, username, role
+		SELECT ` + adminResponseColumns + `
 		FROM admins
 		WHERE id = $1
 	`)
@@ -75,21 +142,17 @@ func (r *PostgresAdminRepository) GetAdminByID(id int32) (*domain.CommonAdminRes
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRowContext(context.TODO(), id)
-
-	var admin domain.CommonAdminResponse
-
-	err = row.Scan(
-		&admin.ID,
-		&admin.Username,
-		&admin.Role,
-	)
+	admin, err := scanAdminResponse(stmt.QueryRowContext(context.TODO(), id))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAdminNotFound
+		}
+
 		slog.Error("error scanning admin row: %v", utils.Err(err))
 		return nil, err
 	}
 
-	return &admin, nil
+	return admin, nil
 }
 
 func (r *PostgresAdminRepository) CreateAdmin(request *domain.CreateAdminRequest) (*domain.CommonAdminResponse, error) {
@@ -107,41 +170,57 @@ func (r *PostgresAdminRepository) CreateAdmin(request *domain.CreateAdminRequest
 		return nil, domain.ErrAdminAlreadyExists
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if request.Email != "" {
+		if err := r.checkEmailAvailable(request.Email, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	hashedPassword, err := r.Hasher.Hash(request.Password)
 	if err != nil {
 		slog.Error("error hashing password: %v", utils.Err(err))
 		return nil, err
 	}
 
-	stmt, err := r.DB.Prepare(`
-		INSERT INTO admins (username, password, role)
-		VALUES ($1, $2, $3)
-		RETURNING id, username, password, role
-	`)
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO admins (username, password, role, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING ` + adminResponseColumns)
 	if err != nil {
 		slog.Error("error preparing query: %v", utils.Err(err))
 		return nil, err
 	}
 	defer stmt.Close()
 
-	var admin domain.CommonAdminResponse
-
-	err = stmt.QueryRow(
+	admin, err := scanAdminResponse(stmt.QueryRow(
 		request.Username,
 		hashedPassword,
 		request.Role,
-	).Scan(
-		&admin.ID,
-		&admin.Username,
-		&hashedPassword,
-		&admin.Role,
-	)
+		request.Email,
+	))
 	if err != nil {
 		slog.Error("error executing query: %v", utils.Err(err))
 		return nil, err
 	}
 
-	return &admin, nil
+	if err := recordPasswordHistory(tx, admin.ID, hashedPassword); err != nil {
+		slog.Error("error recording password history: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return admin, nil
 }
 
 func (r *PostgresAdminRepository) UpdateAdmin(request *domain.UpdateAdminRequest) (*domain.CommonAdminResponse, error) {
@@ -154,14 +233,16 @@ func (r *PostgresAdminRepository) UpdateAdmin(request *domain.UpdateAdminRequest
 		queryParams = append(queryParams, request.Username)
 	}
 
+	var hashedPassword string
 	if request.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		var err error
+		hashedPassword, err = r.Hasher.Hash(request.Password)
 		if err != nil {
 			slog.Error("error hashing new password: %v", utils.Err(err))
 			return nil, err
 		}
 
-		queryArgs = append(queryArgs, "password = $"+strconv.Itoa(len(queryParams)+1))
+		queryArgs = append(queryArgs, "password = $"+strconv.Itoa(len(queryParams)+1), "password_changed_at = CURRENT_TIMESTAMP")
 		queryParams = append(queryParams, hashedPassword)
 	}
 
@@ -170,31 +251,58 @@ func (r *PostgresAdminRepository) UpdateAdmin(request *domain.UpdateAdminRequest
 		queryParams = append(queryParams, request.Role)
 	}
 
+	if request.Email != "" {
+		if err := r.checkEmailAvailable(request.Email, request.ID); err != nil {
+			return nil, err
+		}
+
+		queryArgs = append(queryArgs, "email = $"+strconv.Itoa(len(queryParams)+1))
+		queryParams = append(queryParams, request.Email)
+	}
+
+	if request.RequirePasswordChange != nil {
+		queryArgs = append(queryArgs, "password_change_required = $"+strconv.Itoa(len(queryParams)+1))
+		queryParams = append(queryParams, *request.RequirePasswordChange)
+	}
+
 	updateQuery += " " + strings.Join(queryArgs, ", ") + " WHERE id = $" + strconv.Itoa(len(queryParams)+1)
 	queryParams = append(queryParams, request.ID)
 
-	updateQuery += " RETURNING id, username, role"
+	updateQuery += " RETURNING " + adminResponseColumns
 
-	stmt, err := r.DB.Prepare(updateQuery)
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(updateQuery)
 	if err != nil {
 		slog.Error("error preparing query: %v", utils.Err(err))
 		return nil, err
 	}
 	defer stmt.Close()
 
-	var admin domain.CommonAdminResponse
-
-	err = stmt.QueryRow(queryParams...).Scan(
-		&admin.ID,
-		&admin.Username,
-		&admin.Role,
-	)
+	admin, err := scanAdminResponse(stmt.QueryRow(queryParams...))
 	if err != nil {
 		slog.Error("error executing  query: %v", utils.Err(err))
 		return nil, err
 	}
 
-	return &admin, nil
+	if hashedPassword != "" {
+		if err := recordPasswordHistory(tx, admin.ID, hashedPassword); err != nil {
+			slog.Error("error recording password history: %v", utils.Err(err))
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return admin, nil
 }
 
 func (r *PostgresAdminRepository) DeleteAdmin(id int32) error {
@@ -211,7 +319,7 @@ func (r *PostgresAdminRepository) DeleteAdmin(id int32) error {
 
 	stmt, err := r.DB.Prepare(`DELETE FROM admins WHERE id = $1`)
 	if err != nil {
-		slog.Error("error preparing query: %v", err)
+		slog.Error("error preparing query: %v", utils.Err(err))
 		return err
 	}
 	defer stmt.Close()
@@ -225,45 +333,189 @@ func (r *PostgresAdminRepository) DeleteAdmin(id int32) error {
 	return nil
 }
 
-func (r *PostgresAdminRepository) SearchAdmins(query string, page, pageSize int) (*domain.AdminsList, error) {
-	offset := (page - 1) * pageSize
+// SearchAdmins matches the query against username, role and email, sorted and paged like GetAllAdmins
+func (r *PostgresAdminRepository) SearchAdmins(query, status string, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error) {
+	conditions := []string{`(username ILIKE $1 OR role ILIKE $1 OR email ILIKE $1)`, adminStatusCondition(2)}
+	return r.listAdmins(conditions, []interface{}{"%" + query + "%", status}, sort, page)
+}
+
+// InvalidateAccessTokens rejects every access token of the admin issued until now
+func (r *PostgresAdminRepository) InvalidateAccessTokens(id int32) error {
+	_, err := r.DB.Exec(`UPDATE admins SET tokens_valid_after = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	if err != nil {
+		slog.Error("error invalidating access tokens: %v", utils.Err(err))
+		return err
+	}
 
-	searchQuery := `
-        SELECT id, username, role
-        FROM admins
-        WHERE username ILIKE $1 OR role ILIKE $1
-        ORDER BY id
-        LIMIT $2 OFFSET $3
-    `
+	return nil
+}
 
-	stmt, err := r.DB.Prepare(searchQuery)
+// RevokeAllSessions logs the admin out everywhere, including their outstanding access tokens
+func (r *PostgresAdminRepository) RevokeAllSessions(id int32) error {
+	tx, err := r.DB.Begin()
 	if err != nil {
-		slog.Error("Error preparing search query: %v", utils.Err(err))
-		return nil, err
+		slog.Error("error beginning transaction: %v", utils.Err(err))
+		return err
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	rows, err := stmt.QueryContext(context.TODO(), "%"+query+"%", pageSize, offset)
+	_, err = tx.Exec(`
+		UPDATE admin_sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE admin_id = $1 AND revoked_at IS NULL
+	`, id)
 	if err != nil {
-		slog.Error("Error executing search query: %v", utils.Err(err))
+		slog.Error("error revoking sessions: %v", utils.Err(err))
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE admins SET tokens_valid_after = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	if err != nil {
+		slog.Error("error invalidating access tokens: %v", utils.Err(err))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableAdmin keeps the admin with their history but logs them out everywhere and keeps them out
+func (r *PostgresAdminRepository) DisableAdmin(id, disabledBy int32, reason string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE admins
+		SET disabled = TRUE, disabled_at = CURRENT_TIMESTAMP, disabled_reason = $2,
+		    disabled_by = NULLIF($3, 0), tokens_valid_after = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, reason, disabledBy)
+	if err != nil {
+		slog.Error("error disabling admin: %v", utils.Err(err))
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return domain.ErrAdminNotFound
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admin_sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE admin_id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		slog.Error("error revoking sessions: %v", utils.Err(err))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableDormantAdmins disables every admin without a login since the given time and returns their IDs.
+// Super admins are left alone, so that nobody can lock everyone out.
+func (r *PostgresAdminRepository) DisableDormantAdmins(since time.Time, reason string) ([]int32, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		UPDATE admins
+		SET disabled = TRUE, disabled_at = CURRENT_TIMESTAMP, disabled_reason = $2,
+		    disabled_by = NULL, tokens_valid_after = CURRENT_TIMESTAMP
+		WHERE NOT disabled AND role <> $3 AND COALESCE(last_login_at, created_at) < $1
+		RETURNING id
+	`, since, reason, domain.SuperAdminRole)
+	if err != nil {
+		slog.Error("error disabling dormant admins: %v", utils.Err(err))
 		return nil, err
 	}
-	defer rows.Close()
 
-	adminList := domain.AdminsList{Admins: make([]domain.CommonAdminResponse, 0)}
+	ids := make([]int32, 0)
 	for rows.Next() {
-		var admin domain.CommonAdminResponse
-		if err := rows.Scan(&admin.ID, &admin.Username, &admin.Role); err != nil {
-			slog.Error("Error scanning admin row: %v", utils.Err(err))
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			slog.Error("error scanning dormant admin row: %v", utils.Err(err))
 			return nil, err
 		}
-		adminList.Admins = append(adminList.Admins, admin)
+		ids = append(ids, id)
 	}
+	rows.Close()
 
 	if err := rows.Err(); err != nil {
-		slog.Error("Error iterating over user rows: %v", utils.Err(err))
+		slog.Error("error iterating over dormant admin rows: %v", utils.Err(err))
 		return nil, err
 	}
 
-	return &adminList, nil
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admin_sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE admin_id = ANY($1) AND revoked_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		slog.Error("error revoking sessions: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// EnableAdmin lets a disabled admin sign in again, their old sessions stay revoked
+func (r *PostgresAdminRepository) EnableAdmin(id int32) error {
+	result, err := r.DB.Exec(`
+		UPDATE admins
+		SET disabled = FALSE, disabled_at = NULL, disabled_reason = NULL, disabled_by = NULL
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		slog.Error("error enabling admin: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrAdminNotFound
+	}
+
+	return nil
+}
+
+// checkEmailAvailable fails when another admin than exceptID already uses the email
+func (r *PostgresAdminRepository) checkEmailAvailable(email string, exceptID int32) error {
+	var taken bool
+	err := r.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admins WHERE lower(email) = lower($1) AND id <> $2)
+	`, email, exceptID).Scan(&taken)
+	if err != nil {
+		slog.Error("error checking admin email: %v", utils.Err(err))
+		return err
+	}
+
+	if taken {
+		return domain.ErrAdminEmailExists
+	}
+
+	return nil
 }
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/lib/pq"
+)
+
+type PostgresAPIKeyRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresAPIKeyRepository(db *sql.DB) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{DB: db}
+}
+
+const apiKeyColumns = `id, name, key_prefix, scopes, COALESCE(created_by, 0), created_at, expires_at, last_used_at, COALESCE(last_used_ip, ''), revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var apiKey domain.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		pq.Array(&apiKey.Scopes),
+		&apiKey.CreatedBy,
+		&apiKey.CreatedAt,
+		&expiresAt,
+		&lastUsedAt,
+		&apiKey.LastUsedIP,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		apiKey.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		apiKey.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = &revokedAt.Time
+	}
+
+	return &apiKey, nil
+}
+
+// CreateAPIKey stores the key's hash, the plain key is never persisted
+func (r *PostgresAPIKeyRepository) CreateAPIKey(apiKey *domain.APIKey, key string) (*domain.APIKey, error) {
+	query := `
+        INSERT INTO api_keys (name, key_prefix, key_hash, scopes, created_by, expires_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6)
+        RETURNING ` + apiKeyColumns
+
+	created, err := scanAPIKey(r.DB.QueryRow(
+		query,
+		apiKey.Name,
+		apiKey.Prefix,
+		hashToken(key),
+		pq.Array(apiKey.Scopes),
+		apiKey.CreatedBy,
+		apiKey.ExpiresAt,
+	))
+	if err != nil {
+		slog.Error("Error creating api key: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetActiveAPIKey looks the key up by its hash, ignoring revoked and expired keys
+func (r *PostgresAPIKeyRepository) GetActiveAPIKey(key string) (*domain.APIKey, error) {
+	query := `
+        SELECT ` + apiKeyColumns + `
+        FROM api_keys
+        WHERE key_hash = $1
+            AND revoked_at IS NULL
+            AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+    `
+
+	apiKey, err := scanAPIKey(r.DB.QueryRow(query, hashToken(key)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidAPIKey
+		}
+
+		slog.Error("Error getting api key: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// TouchAPIKey records the use of a key, at most once a minute to keep writes off the hot path
+func (r *PostgresAPIKeyRepository) TouchAPIKey(id int32, ipAddress string) error {
+	_, err := r.DB.Exec(`
+		UPDATE api_keys
+		SET last_used_at = CURRENT_TIMESTAMP, last_used_ip = $2
+		WHERE id = $1
+			AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute' OR last_used_ip <> $2)
+	`, id, ipAddress)
+	if err != nil {
+		slog.Error("Error updating api key usage: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresAPIKeyRepository) GetAPIKeys() (*domain.APIKeysList, error) {
+	rows, err := r.DB.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`)
+	if err != nil {
+		slog.Error("Error getting api keys: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := domain.APIKeysList{APIKeys: make([]domain.APIKey, 0)}
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			slog.Error("Error scanning api key row: %v", utils.Err(err))
+			return nil, err
+		}
+		list.APIKeys = append(list.APIKeys, *apiKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over api key rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (r *PostgresAPIKeyRepository) RevokeAPIKey(id int32) error {
+	result, err := r.DB.Exec(`
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		slog.Error("Error revoking api key: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"time"
@@ -9,6 +11,8 @@ import (
 	"user-admin/pkg/lib/utils"
 
 	"user-admin/internal/config"
+	"user-admin/pkg/jwtkeys"
+	"user-admin/pkg/password"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
@@ -17,26 +21,31 @@ import (
 type PostgresAdminAuthRepository struct {
 	DB        *sql.DB
 	JWTConfig config.JWT
+	KeySet    *jwtkeys.KeySet
+	Hasher    *password.Hasher
 }
 
-func NewPostgresAdminAuthRepository(db *sql.DB, jwtConfig config.JWT) *PostgresAdminAuthRepository {
-	return &PostgresAdminAuthRepository{DB: db, JWTConfig: jwtConfig}
+func NewPostgresAdminAuthRepository(db *sql.DB, jwtConfig config.JWT, keySet *jwtkeys.KeySet, hasher *password.Hasher) *PostgresAdminAuthRepository {
+	return &PostgresAdminAuthRepository{DB: db, JWTConfig: jwtConfig, KeySet: keySet, Hasher: hasher}
 }
 
 const (
-	accessTokenExpiration  = 30 * time.Minute
-	refreshTokenExpiration = 7 * 24 * time.Hour
+	accessTokenExpiration     = 30 * time.Minute
+	refreshTokenExpiration    = 7 * 24 * time.Hour
+	restrictedTokenExpiration = 5 * time.Minute
+	// Impersonation tokens cannot be refreshed, the impersonator has to ask for a new one
+	impersonationTokenExpiration = 15 * time.Minute
 )
 
-func (r *PostgresAdminAuthRepository) GenerateTokenPair(admin *domain.Admin) (string, string, error) {
+func (r *PostgresAdminAuthRepository) GenerateTokenPair(admin *domain.Admin, sessionID string) (string, string, error) {
 	// Generate a new access token
-	accessToken, err := r.generateAccessToken(admin)
+	accessToken, err := r.generateAccessToken(admin, sessionID)
 	if err != nil {
 		return "", "", err
 	}
 
-	// Generate a new refresh token
-	refreshToken, err := r.generateRefreshToken(admin)
+	// Generate a new refresh token bound to the session
+	refreshToken, err := r.generateRefreshToken(admin, sessionID)
 	if err != nil {
 		return "", "", err
 	}
@@ -45,26 +54,36 @@ func (r *PostgresAdminAuthRepository) GenerateTokenPair(admin *domain.Admin) (st
 }
 
 func (r *PostgresAdminAuthRepository) ValidateRefreshToken(refreshToken string) (map[string]interface{}, error) {
-	token, _, err := new(jwt.Parser).ParseUnverified(refreshToken, jwt.MapClaims{})
+	claims, err := r.validateRefreshToken(refreshToken)
 	if err != nil {
-		slog.Error("Error parsing refresh token: %v", err)
-		return nil, fmt.Errorf("error parsing refresh token: %v", err)
+		return nil, err
 	}
 
-	adminIDClaim, ok := token.Claims.(jwt.MapClaims)["adminID"]
+	adminIDClaim, ok := claims["adminID"]
 	if !ok {
 		slog.Error("AdminID claim not found in refresh token")
 		return nil, fmt.Errorf("adminID claim not found in refresh token")
 	}
 
+	sessionIDClaim, ok := claims["sid"].(string)
+	if !ok {
+		slog.Error("Session ID claim not found in refresh token")
+		return nil, fmt.Errorf("session ID claim not found in refresh token")
+	}
+
+	// Used tokens are still reported as valid here so that the caller can detect their reuse
 	query := `
-        SELECT 1
-        FROM admins
-        WHERE refresh_token = $1 AND id = $2
+        SELECT EXISTS(
+            SELECT 1
+            FROM admin_refresh_tokens t
+            JOIN admin_sessions s ON s.id = t.session_id
+            WHERE t.token_hash = $1 AND s.id = $2 AND s.admin_id = $3
+                AND s.revoked_at IS NULL AND t.expires_at > CURRENT_TIMESTAMP
+        )
     `
 
 	var exists bool
-	err = r.DB.QueryRow(query, refreshToken, adminIDClaim).Scan(&exists)
+	err = r.DB.QueryRow(query, hashToken(refreshToken), sessionIDClaim, adminIDClaim).Scan(&exists)
 	if err != nil {
 		slog.Error("Error checking refresh token existence in database: %v", utils.Err(err))
 		return nil, fmt.Errorf("error checking refresh token existence in database: %v", err)
@@ -75,24 +94,49 @@ func (r *PostgresAdminAuthRepository) ValidateRefreshToken(refreshToken string)
 		return nil, fmt.Errorf("refresh token not found in the database")
 	}
 
-	claims, err := r.validateRefreshToken(refreshToken)
+	return claims, nil
+}
+
+// MarkRefreshTokenUsed rotates a refresh token out of its family. It returns
+// domain.ErrRefreshTokenReused when the token had already been used before.
+func (r *PostgresAdminAuthRepository) MarkRefreshTokenUsed(refreshToken string) error {
+	query := `
+        UPDATE admin_refresh_tokens
+        SET used_at = CURRENT_TIMESTAMP
+        WHERE token_hash = $1 AND used_at IS NULL
+    `
+
+	result, err := r.DB.Exec(query, hashToken(refreshToken))
 	if err != nil {
-		return nil, err
+		slog.Error("Error marking refresh token as used: %v", utils.Err(err))
+		return err
 	}
 
-	return claims, nil
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking used refresh token: %v", utils.Err(err))
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+
+	return nil
 }
 
 func (r *PostgresAdminAuthRepository) DeleteRefreshToken(refreshToken string) error {
 	query := `
-        UPDATE admins
-        SET refresh_token = NULL,
-            refresh_token_created_at = NULL,
-            refresh_token_expiration_time = NULL
-        WHERE refresh_token = $1
+        UPDATE admin_sessions
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE revoked_at IS NULL AND id = (
+            SELECT session_id
+            FROM admin_refresh_tokens
+            WHERE token_hash = $1
+        )
     `
 
-	_, err := r.DB.Exec(query, refreshToken)
+	_, err := r.DB.Exec(query, hashToken(refreshToken))
 	if err != nil {
 		slog.Error("Error deleting refresh token: %v", utils.Err(err))
 		return err
@@ -101,68 +145,279 @@ func (r *PostgresAdminAuthRepository) DeleteRefreshToken(refreshToken string) er
 	return nil
 }
 
-func (r *PostgresAdminAuthRepository) GetAdminByUsername(username string) (*domain.Admin, error) {
+func (r *PostgresAdminAuthRepository) CreateSession(adminID int32, client domain.ClientInfo) (*domain.Session, error) {
+	query := `
+        INSERT INTO admin_sessions (id, admin_id, user_agent, ip_address, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, admin_id, user_agent, ip_address, created_at, last_used_at, expires_at
+    `
+
+	var session domain.Session
+
+	err := r.DB.QueryRow(
+		query,
+		uuid.New().String(),
+		adminID,
+		client.UserAgent,
+		client.IPAddress,
+		time.Now().Add(refreshTokenExpiration),
+	).Scan(
+		&session.ID,
+		&session.AdminID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		slog.Error("Error creating session: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *PostgresAdminAuthRepository) GetSessionsByAdminID(adminID int32) ([]domain.Session, error) {
+	query := `
+        SELECT id, admin_id, user_agent, ip_address, created_at, last_used_at, expires_at
+        FROM admin_sessions
+        WHERE admin_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+        ORDER BY last_used_at DESC
+    `
+
+	rows, err := r.DB.Query(query, adminID)
+	if err != nil {
+		slog.Error("Error getting sessions: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.Session, 0)
+	for rows.Next() {
+		var session domain.Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.AdminID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			slog.Error("Error scanning session row: %v", utils.Err(err))
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over session rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *PostgresAdminAuthRepository) RevokeSession(adminID int32, sessionID string) error {
 	query := `
-		SELECT id, username, password, role
-		FROM admins
-		WHERE username = $1
-		LIMIT 1
-	`
+        UPDATE admin_sessions
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND admin_id = $2 AND revoked_at IS NULL
+    `
+
+	result, err := r.DB.Exec(query, sessionID, adminID)
+	if err != nil {
+		slog.Error("Error revoking session: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking revoked session: %v", utils.Err(err))
+		return err
+	}
 
-	row := r.DB.QueryRow(query, username)
+	if affected == 0 {
+		return domain.ErrSessionNotFound
+	}
 
+	return nil
+}
+
+// adminColumns lists the admin columns read by scanAdmin, in order
+const adminColumns = `id, username, password, role, COALESCE(email, ''), password_changed_at, password_change_required, disabled`
+
+func scanAdmin(row *sql.Row) (*domain.Admin, error) {
 	var admin domain.Admin
 
-	err := row.Scan(&admin.ID, &admin.Username, &admin.Password, &admin.Role)
+	err := row.Scan(
+		&admin.ID,
+		&admin.Username,
+		&admin.Password,
+		&admin.Role,
+		&admin.Email,
+		&admin.PasswordChangedAt,
+		&admin.PasswordChangeRequired,
+		&admin.Disabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
+func (r *PostgresAdminAuthRepository) GetAdminByUsername(username string) (*domain.Admin, error) {
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE username = $1 LIMIT 1`
+
+	admin, err := scanAdmin(r.DB.QueryRow(query, username))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			slog.Error("Admin was not found")
 			return nil, domain.ErrAdminNotFound
 		}
 
-		slog.Error("Error getting admin by username: %v", err)
+		slog.Error("Error getting admin by username: %v", utils.Err(err))
 		return nil, err
 	}
 
-	return &admin, nil
+	return admin, nil
 }
 
 func (r *PostgresAdminAuthRepository) GetAdminByID(adminID int) (*domain.Admin, error) {
-	query := `
-		SELECT id, username, password, role
-		FROM admins
-		WHERE id = $1
-		LIMIT 1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE id = $1`
 
-	row := r.DB.QueryRow(query, adminID)
+	admin, err := scanAdmin(r.DB.QueryRow(query, adminID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			slog.Error("Admin not found")
+			return nil, domain.ErrAdminNotFound
+		}
 
-	var admin domain.Admin
+		slog.Error("Error getting admin by ID: %v", utils.Err(err))
+		return nil, err
+	}
 
-	err := row.Scan(&admin.ID, &admin.Username, &admin.Password, &admin.Role)
+	return admin, nil
+}
+
+// GetAdminByEmail matches the email case-insensitively
+func (r *PostgresAdminAuthRepository) GetAdminByEmail(email string) (*domain.Admin, error) {
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE lower(email) = lower($1) LIMIT 1`
+
+	admin, err := scanAdmin(r.DB.QueryRow(query, email))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			slog.Error("Admin not found")
 			return nil, domain.ErrAdminNotFound
 		}
 
-		slog.Error("Error getting admin by ID: %v", err)
+		slog.Error("Error getting admin by email: %v", utils.Err(err))
 		return nil, err
 	}
 
-	return &admin, nil
+	return admin, nil
+}
+
+// GenerateRestrictedToken issues a short-lived token that only allows finishing the given login step
+func (r *PostgresAdminAuthRepository) GenerateRestrictedToken(admin *domain.Admin, purpose string) (string, error) {
+	claims := jwt.MapClaims{
+		"id":      admin.ID,
+		"role":    admin.Role,
+		"purpose": purpose,
+		"jti":     uuid.New().String(),
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(restrictedTokenExpiration).Unix(),
+	}
+
+	tokenString, err := r.KeySet.Sign(claims)
+	if err != nil {
+		slog.Error("Error generating restricted token: %v", utils.Err(err))
+		return "", err
+	}
+
+	return tokenString, nil
 }
 
-func (r *PostgresAdminAuthRepository) generateAccessToken(admin *domain.Admin) (string, error) {
+// GenerateImpersonationToken issues an access token for the admin that names the impersonating
+// admin in its act claim. It belongs to the session of the impersonator, so it ends with it.
+func (r *PostgresAdminAuthRepository) GenerateImpersonationToken(admin *domain.Admin, impersonatorID int32, sessionID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(impersonationTokenExpiration)
+
 	claims := jwt.MapClaims{
 		"id":   admin.ID,
 		"role": admin.Role,
-		"exp":  time.Now().Add(accessTokenExpiration).Unix(), // Token expiration time
+		"sid":  sessionID,
+		"act": map[string]interface{}{
+			"id": impersonatorID,
+		},
+		"jti": uuid.New().String(),
+		"iat": time.Now().Unix(),
+		"exp": expiresAt.Unix(),
+	}
+
+	tokenString, err := r.KeySet.Sign(claims)
+	if err != nil {
+		slog.Error("Error generating impersonation token: %v", utils.Err(err))
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+func (r *PostgresAdminAuthRepository) ValidateMFAToken(mfaToken, purpose string) (map[string]interface{}, error) {
+	claims, err := r.KeySet.Parse(mfaToken)
+	if err != nil {
+		slog.Error("MFA token validation error: %v", utils.Err(err))
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	if claims["purpose"] != purpose {
+		slog.Error("MFA token has an unexpected purpose")
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	return claims, nil
+}
+
+// IsAccessTokenRevoked reports whether the admin is gone or disabled, invalidated their tokens
+// after issuedAt, or the session the token belongs to has been revoked
+func (r *PostgresAdminAuthRepository) IsAccessTokenRevoked(adminID int32, sessionID string, issuedAt time.Time) (bool, error) {
+	query := `
+        SELECT NOT EXISTS(
+                SELECT 1
+                FROM admins
+                WHERE id = $1 AND NOT disabled
+                    AND (tokens_valid_after IS NULL OR date_trunc('second', tokens_valid_after) <= $2)
+            )
+            OR EXISTS(
+                SELECT 1
+                FROM admin_sessions
+                WHERE id = NULLIF($3, '')::uuid AND revoked_at IS NOT NULL
+            )
+    `
+
+	var revoked bool
+	err := r.DB.QueryRow(query, adminID, issuedAt, sessionID).Scan(&revoked)
+	if err != nil {
+		slog.Error("Error checking access token revocation: %v", utils.Err(err))
+		return false, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return revoked, nil
+}
+
+func (r *PostgresAdminAuthRepository) generateAccessToken(admin *domain.Admin, sessionID string) (string, error) {
+	claims := jwt.MapClaims{
+		"id":   admin.ID,
+		"role": admin.Role,
+		"sid":  sessionID,
+		"jti":  uuid.New().String(),
+		"iat":  time.Now().Unix(),
+		"exp":  time.Now().Add(accessTokenExpiration).Unix(), // Token expiration time
+	}
 
-	tokenString, err := token.SignedString([]byte(r.JWTConfig.AccessSecretKey))
+	tokenString, err := r.KeySet.Sign(claims)
 	if err != nil {
 		slog.Error("Error generating access token: %v", utils.Err(err))
 		return "", err
@@ -171,12 +426,13 @@ func (r *PostgresAdminAuthRepository) generateAccessToken(admin *domain.Admin) (
 	return tokenString, nil
 }
 
-func (r *PostgresAdminAuthRepository) generateRefreshToken(admin *domain.Admin) (string, error) {
+func (r *PostgresAdminAuthRepository) generateRefreshToken(admin *domain.Admin, sessionID string) (string, error) {
 	refreshTokenID := uuid.New().String()
 
 	refreshClaims := jwt.MapClaims{
 		"id":      refreshTokenID,
 		"adminID": admin.ID,
+		"sid":     sessionID,
 		"exp":     time.Now().Add(refreshTokenExpiration).Unix(),
 	}
 
@@ -187,17 +443,49 @@ func (r *PostgresAdminAuthRepository) generateRefreshToken(admin *domain.Admin)
 		return "", err
 	}
 
-	query := `
-        UPDATE admins
-        SET refresh_token = $1,
-            refresh_token_created_at = CURRENT_TIMESTAMP,
-            refresh_token_expiration_time = TO_TIMESTAMP($2)
-        WHERE id = $3
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Failed to begin transaction", utils.Err(err))
+		return "", err
+	}
+	defer tx.Rollback()
+
+	sessionQuery := `
+        UPDATE admin_sessions
+        SET last_used_at = CURRENT_TIMESTAMP,
+            expires_at = TO_TIMESTAMP($1)
+        WHERE id = $2 AND admin_id = $3 AND revoked_at IS NULL
+    `
+
+	result, err := tx.Exec(sessionQuery, refreshClaims["exp"].(int64), sessionID, admin.ID)
+	if err != nil {
+		slog.Error("Failed to update session in database", utils.Err(err))
+		return "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Failed to check updated session", utils.Err(err))
+		return "", err
+	}
+
+	if affected == 0 {
+		return "", domain.ErrSessionNotFound
+	}
+
+	tokenQuery := `
+        INSERT INTO admin_refresh_tokens (id, session_id, token_hash, expires_at)
+        VALUES ($1, $2, $3, TO_TIMESTAMP($4))
     `
 
-	_, err = r.DB.Exec(query, refreshTokenString, refreshClaims["exp"].(int64), admin.ID)
+	_, err = tx.Exec(tokenQuery, refreshTokenID, sessionID, hashToken(refreshTokenString), refreshClaims["exp"].(int64))
 	if err != nil {
-		slog.Error("Failed to update refresh token in database", utils.Err(err))
+		slog.Error("Failed to store refresh token in database", utils.Err(err))
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to commit refresh token", utils.Err(err))
 		return "", err
 	}
 
@@ -213,7 +501,7 @@ func (r *PostgresAdminAuthRepository) validateRefreshToken(refreshToken string)
 	})
 
 	if err != nil || !token.Valid {
-		slog.Error("Refresh token validation error: %v !BADKEY=\"%s\"", err, r.JWTConfig.RefreshSecretKey)
+		slog.Error("Refresh token validation error: %v", utils.Err(err))
 		return nil, fmt.Errorf("refresh token validation error: %v", err)
 	}
 
@@ -225,3 +513,89 @@ func (r *PostgresAdminAuthRepository) validateRefreshToken(refreshToken string)
 
 	return claims, nil
 }
+
+// hashToken returns the hex-encoded SHA-256 digest under which a token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// UpdatePassword sets a new password, clears a pending forced change and logs the admin out everywhere
+func (r *PostgresAdminAuthRepository) UpdatePassword(adminID int32, password string) error {
+	hashedPassword, err := r.Hasher.Hash(password)
+	if err != nil {
+		slog.Error("Error hashing password: %v", utils.Err(err))
+		return err
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE admins
+		SET password = $1,
+			password_changed_at = CURRENT_TIMESTAMP,
+			password_change_required = FALSE,
+			tokens_valid_after = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, hashedPassword, adminID)
+	if err != nil {
+		slog.Error("Error updating password: %v", utils.Err(err))
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admin_sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE admin_id = $1 AND revoked_at IS NULL
+	`, adminID)
+	if err != nil {
+		slog.Error("Error revoking sessions: %v", utils.Err(err))
+		return err
+	}
+
+	if err := recordPasswordHistory(tx, adminID, hashedPassword); err != nil {
+		slog.Error("Error recording password history: %v", utils.Err(err))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RehashPassword stores the password again with the current hashing algorithm and parameters.
+// It leaves the admin untouched if their password hash changed since oldHash was read.
+func (r *PostgresAdminAuthRepository) RehashPassword(adminID int32, oldHash, password string) error {
+	hashedPassword, err := r.Hasher.Hash(password)
+	if err != nil {
+		slog.Error("Error hashing password: %v", utils.Err(err))
+		return err
+	}
+
+	_, err = r.DB.Exec(`UPDATE admins SET password = $1 WHERE id = $2 AND password = $3`, hashedPassword, adminID, oldHash)
+	if err != nil {
+		slog.Error("Error rehashing password: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// RecordActivity sets when the admin was last seen, at most once a minute to keep writes off the hot path
+func (r *PostgresAdminAuthRepository) RecordActivity(adminID int32) error {
+	_, err := r.DB.Exec(`
+		UPDATE admins
+		SET last_seen_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+			AND (last_seen_at IS NULL OR last_seen_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`, adminID)
+	if err != nil {
+		slog.Error("Error recording admin activity: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
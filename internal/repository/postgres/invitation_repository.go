@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/password"
+)
+
+type PostgresInvitationRepository struct {
+	DB     *sql.DB
+	Hasher *password.Hasher
+}
+
+func NewPostgresInvitationRepository(db *sql.DB, hasher *password.Hasher) *PostgresInvitationRepository {
+	return &PostgresInvitationRepository{DB: db, Hasher: hasher}
+}
+
+const invitationColumns = `id, username, COALESCE(email, ''), role, COALESCE(invited_by, 0), created_at, expires_at, accepted_at, COALESCE(admin_id, 0), revoked_at`
+
+// pendingInvitation matches invitations that can still be accepted
+const pendingInvitation = `accepted_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+
+func scanInvitation(row rowScanner) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	var acceptedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Username,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.InvitedBy,
+		&invitation.CreatedAt,
+		&invitation.ExpiresAt,
+		&acceptedAt,
+		&invitation.AdminID,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case acceptedAt.Valid:
+		invitation.AcceptedAt = &acceptedAt.Time
+		invitation.Status = domain.InvitationStatusAccepted
+	case revokedAt.Valid:
+		invitation.RevokedAt = &revokedAt.Time
+		invitation.Status = domain.InvitationStatusRevoked
+	case invitation.ExpiresAt.Before(time.Now()):
+		invitation.Status = domain.InvitationStatusExpired
+	default:
+		invitation.Status = domain.InvitationStatusPending
+	}
+
+	return &invitation, nil
+}
+
+// CreateInvitation stores the hash of the token. The username and email must be free, both among
+// admins and open invitations; an expired invitation for the same username is revoked.
+func (r *PostgresInvitationRepository) CreateInvitation(invitation *domain.Invitation, token string) (*domain.Invitation, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var adminExists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admins WHERE username = $1)
+	`, invitation.Username).Scan(&adminExists)
+	if err != nil {
+		slog.Error("Error checking admin existence: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if adminExists {
+		return nil, domain.ErrAdminAlreadyExists
+	}
+
+	if invitation.Email != "" {
+		var emailTaken bool
+		err = tx.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM admins WHERE lower(email) = lower($1))
+				OR EXISTS(SELECT 1 FROM admin_invitations WHERE lower(email) = lower($1) AND `+pendingInvitation+`)
+		`, invitation.Email).Scan(&emailTaken)
+		if err != nil {
+			slog.Error("Error checking admin email: %v", utils.Err(err))
+			return nil, err
+		}
+
+		if emailTaken {
+			return nil, domain.ErrAdminEmailExists
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admin_invitations
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE username = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= CURRENT_TIMESTAMP
+	`, invitation.Username)
+	if err != nil {
+		slog.Error("Error revoking expired invitations: %v", utils.Err(err))
+		return nil, err
+	}
+
+	var openExists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admin_invitations WHERE username = $1 AND accepted_at IS NULL AND revoked_at IS NULL)
+	`, invitation.Username).Scan(&openExists)
+	if err != nil {
+		slog.Error("Error checking open invitations: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if openExists {
+		return nil, domain.ErrInvitationExists
+	}
+
+	query := `
+        INSERT INTO admin_invitations (username, email, role, token_hash, invited_by, expires_at)
+        VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, 0), $6)
+        RETURNING ` + invitationColumns
+
+	created, err := scanInvitation(tx.QueryRow(
+		query,
+		invitation.Username,
+		invitation.Email,
+		invitation.Role,
+		hashToken(token),
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	))
+	if err != nil {
+		slog.Error("Error creating invitation: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *PostgresInvitationRepository) GetInvitations() (*domain.InvitationsList, error) {
+	rows, err := r.DB.Query(`SELECT ` + invitationColumns + ` FROM admin_invitations ORDER BY id DESC`)
+	if err != nil {
+		slog.Error("Error getting invitations: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := domain.InvitationsList{Invitations: make([]domain.Invitation, 0)}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			slog.Error("Error scanning invitation row: %v", utils.Err(err))
+			return nil, err
+		}
+		list.Invitations = append(list.Invitations, *invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over invitation rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// GetPendingInvitation returns the invitation of a token that can still be accepted
+func (r *PostgresInvitationRepository) GetPendingInvitation(token string) (*domain.Invitation, error) {
+	query := `
+        SELECT ` + invitationColumns + `
+        FROM admin_invitations
+        WHERE token_hash = $1 AND ` + pendingInvitation
+
+	invitation, err := scanInvitation(r.DB.QueryRow(query, hashToken(token)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidInvitationToken
+		}
+
+		slog.Error("Error getting invitation: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// RenewInvitation replaces the token of an open invitation, the previous token stops working
+func (r *PostgresInvitationRepository) RenewInvitation(id int32, token string, expiresAt time.Time) (*domain.Invitation, error) {
+	query := `
+        UPDATE admin_invitations
+        SET token_hash = $2, expires_at = $3
+        WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+        RETURNING ` + invitationColumns
+
+	invitation, err := scanInvitation(r.DB.QueryRow(query, id, hashToken(token), expiresAt))
+	if err == sql.ErrNoRows {
+		return nil, r.invitationNotOpen(id)
+	}
+	if err != nil {
+		slog.Error("Error renewing invitation: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (r *PostgresInvitationRepository) RevokeInvitation(id int32) (*domain.Invitation, error) {
+	query := `
+        UPDATE admin_invitations
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+        RETURNING ` + invitationColumns
+
+	invitation, err := scanInvitation(r.DB.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, r.invitationNotOpen(id)
+	}
+	if err != nil {
+		slog.Error("Error revoking invitation: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation creates the admin with the chosen password and spends the token, both or neither
+func (r *PostgresInvitationRepository) AcceptInvitation(token, password string) (*domain.CommonAdminResponse, error) {
+	hashedPassword, err := r.Hasher.Hash(password)
+	if err != nil {
+		slog.Error("Error hashing password: %v", utils.Err(err))
+		return nil, err
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the row makes a concurrent accept with the same token wait and then find it spent
+	var invitationID int32
+	var username, email, role string
+	err = tx.QueryRow(`
+		SELECT id, username, COALESCE(email, ''), role
+		FROM admin_invitations
+		WHERE token_hash = $1 AND `+pendingInvitation+`
+		FOR UPDATE
+	`, hashToken(token)).Scan(&invitationID, &username, &email, &role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidInvitationToken
+		}
+
+		slog.Error("Error getting invitation: %v", utils.Err(err))
+		return nil, err
+	}
+
+	var adminExists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admins WHERE username = $1)
+	`, username).Scan(&adminExists)
+	if err != nil {
+		slog.Error("Error checking admin existence: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if adminExists {
+		return nil, domain.ErrAdminAlreadyExists
+	}
+
+	admin, err := scanAdminResponse(tx.QueryRow(`
+		INSERT INTO admins (username, password, role, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING `+adminResponseColumns, username, hashedPassword, role, email))
+	if err != nil {
+		slog.Error("Error creating admin: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if err := recordPasswordHistory(tx, admin.ID, hashedPassword); err != nil {
+		slog.Error("Error recording password history: %v", utils.Err(err))
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admin_invitations
+		SET accepted_at = CURRENT_TIMESTAMP, admin_id = $2
+		WHERE id = $1
+	`, invitationID, admin.ID)
+	if err != nil {
+		slog.Error("Error accepting invitation: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// invitationNotOpen tells a missing invitation apart from one that was accepted or revoked
+func (r *PostgresInvitationRepository) invitationNotOpen(id int32) error {
+	var exists bool
+	err := r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM admin_invitations WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		slog.Error("Error checking invitation existence: %v", utils.Err(err))
+		return err
+	}
+
+	if !exists {
+		return domain.ErrInvitationNotFound
+	}
+
+	return domain.ErrInvitationNotPending
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresLoginAttemptRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresLoginAttemptRepository(db *sql.DB) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{DB: db}
+}
+
+// GetLoginAttempt returns an empty attempt when the key has no failures recorded
+func (r *PostgresLoginAttemptRepository) GetLoginAttempt(key string) (*domain.LoginAttempt, error) {
+	query := `
+        SELECT key, failures, last_failure_at, locked_until
+        FROM login_attempts
+        WHERE key = $1
+    `
+
+	attempt, err := scanLoginAttempt(r.DB.QueryRow(query, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &domain.LoginAttempt{Key: key}, nil
+		}
+
+		slog.Error("Error getting login attempt: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// RegisterFailedLogin increments the failure counter, starting over when the
+// previous failure is older than window
+func (r *PostgresLoginAttemptRepository) RegisterFailedLogin(key string, window time.Duration) (*domain.LoginAttempt, error) {
+	query := `
+        INSERT INTO login_attempts (key, failures, last_failure_at)
+        VALUES ($1, 1, CURRENT_TIMESTAMP)
+        ON CONFLICT (key) DO UPDATE
+        SET failures = CASE
+                WHEN login_attempts.last_failure_at < CURRENT_TIMESTAMP - make_interval(secs => $2) THEN 1
+                ELSE login_attempts.failures + 1
+            END,
+            last_failure_at = CURRENT_TIMESTAMP
+        RETURNING key, failures, last_failure_at, locked_until
+    `
+
+	attempt, err := scanLoginAttempt(r.DB.QueryRow(query, key, window.Seconds()))
+	if err != nil {
+		slog.Error("Error registering failed login: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+func (r *PostgresLoginAttemptRepository) LockLogin(key string, until time.Time) error {
+	_, err := r.DB.Exec(`UPDATE login_attempts SET locked_until = $2 WHERE key = $1`, key, until)
+	if err != nil {
+		slog.Error("Error locking login: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresLoginAttemptRepository) ResetLoginAttempts(key string) error {
+	_, err := r.DB.Exec(`DELETE FROM login_attempts WHERE key = $1`, key)
+	if err != nil {
+		slog.Error("Error resetting login attempts: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+func scanLoginAttempt(row *sql.Row) (*domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	var lockedUntil sql.NullTime
+
+	if err := row.Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		attempt.LockedUntil = lockedUntil.Time
+	}
+
+	return &attempt, nil
+}
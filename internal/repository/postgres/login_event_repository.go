@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresLoginEventRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresLoginEventRepository(db *sql.DB) *PostgresLoginEventRepository {
+	return &PostgresLoginEventRepository{DB: db}
+}
+
+// loginEventColumns fall back to the admin's current username for attempts recorded without one
+const loginEventColumns = `
+    e.id, COALESCE(e.admin_id, 0), COALESCE(NULLIF(e.username, ''), a.username, ''), e.result, e.method,
+    COALESCE(e.ip_address, ''), COALESCE(e.user_agent, ''), e.created_at`
+
+// CreateLoginEvent records the attempt, a successful one also becomes the admin's last login
+func (r *PostgresLoginEventRepository) CreateLoginEvent(event *domain.LoginEvent) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO login_events (admin_id, username, result, method, ip_address, user_agent)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, event.AdminID, event.Username, event.Result, event.Method, event.IPAddress, event.UserAgent).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		slog.Error("Error creating login event: %v", utils.Err(err))
+		return err
+	}
+
+	if event.Result == domain.LoginResultSuccess && event.AdminID != 0 {
+		_, err = tx.Exec(`
+			UPDATE admins SET last_login_at = $2, last_seen_at = $2 WHERE id = $1
+		`, event.AdminID, event.CreatedAt)
+		if err != nil {
+			slog.Error("Error updating last login: %v", utils.Err(err))
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLoginEvents lists the admin's login attempts, most recent first
+func (r *PostgresLoginEventRepository) GetLoginEvents(adminID int32, page, pageSize int) (*domain.LoginEventsList, error) {
+	offset := (page - 1) * pageSize
+
+	query := `
+        SELECT ` + loginEventColumns + `
+        FROM login_events e
+        LEFT JOIN admins a ON a.id = e.admin_id
+        WHERE e.admin_id = $1
+        ORDER BY e.created_at DESC, e.id DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	return r.queryLoginEvents(query, adminID, pageSize, offset)
+}
+
+// GetLoginFailures lists every failed attempt since the given time, most recent first
+func (r *PostgresLoginEventRepository) GetLoginFailures(since time.Time, page, pageSize int) (*domain.LoginEventsList, error) {
+	offset := (page - 1) * pageSize
+
+	query := `
+        SELECT ` + loginEventColumns + `
+        FROM login_events e
+        LEFT JOIN admins a ON a.id = e.admin_id
+        WHERE e.result <> 'success' AND e.created_at >= $1
+        ORDER BY e.created_at DESC, e.id DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	return r.queryLoginEvents(query, since, pageSize, offset)
+}
+
+func (r *PostgresLoginEventRepository) queryLoginEvents(query string, args ...interface{}) (*domain.LoginEventsList, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		slog.Error("Error getting login events: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := domain.LoginEventsList{Events: make([]domain.LoginEvent, 0)}
+	for rows.Next() {
+		var event domain.LoginEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.AdminID,
+			&event.Username,
+			&event.Result,
+			&event.Method,
+			&event.IPAddress,
+			&event.UserAgent,
+			&event.CreatedAt,
+		); err != nil {
+			slog.Error("Error scanning login event row: %v", utils.Err(err))
+			return nil, err
+		}
+		list.Events = append(list.Events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over login event rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &list, nil
+}
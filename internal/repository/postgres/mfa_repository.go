@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresMFARepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresMFARepository(db *sql.DB) *PostgresMFARepository {
+	return &PostgresMFARepository{DB: db}
+}
+
+func (r *PostgresMFARepository) GetMFA(adminID int32) (*domain.AdminMFA, error) {
+	query := `
+        SELECT admin_id, secret, enabled, last_used_step, created_at
+        FROM admin_mfa
+        WHERE admin_id = $1
+    `
+
+	var mfa domain.AdminMFA
+
+	err := r.DB.QueryRow(query, adminID).Scan(
+		&mfa.AdminID,
+		&mfa.Secret,
+		&mfa.Enabled,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrMFANotEnrolled
+		}
+
+		slog.Error("Error getting admin MFA: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &mfa, nil
+}
+
+// SaveMFASecret starts a new enrollment, replacing any unconfirmed one
+func (r *PostgresMFARepository) SaveMFASecret(adminID int32, secret string) error {
+	query := `
+        INSERT INTO admin_mfa (admin_id, secret)
+        VALUES ($1, $2)
+        ON CONFLICT (admin_id) DO UPDATE
+        SET secret = EXCLUDED.secret,
+            last_used_step = 0,
+            created_at = CURRENT_TIMESTAMP
+        WHERE admin_mfa.enabled = FALSE
+    `
+
+	result, err := r.DB.Exec(query, adminID, secret)
+	if err != nil {
+		slog.Error("Error saving MFA secret: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking saved MFA secret: %v", utils.Err(err))
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
+
+// EnableMFA confirms the enrollment and replaces the recovery codes of the admin
+func (r *PostgresMFARepository) EnableMFA(adminID int32, recoveryCodes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+        UPDATE admin_mfa
+        SET enabled = TRUE, confirmed_at = CURRENT_TIMESTAMP
+        WHERE admin_id = $1 AND enabled = FALSE
+    `, adminID)
+	if err != nil {
+		slog.Error("Error enabling MFA: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking enabled MFA: %v", utils.Err(err))
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	if _, err := tx.Exec(`DELETE FROM admin_recovery_codes WHERE admin_id = $1`, adminID); err != nil {
+		slog.Error("Error deleting recovery codes: %v", utils.Err(err))
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO admin_recovery_codes (admin_id, code_hash) VALUES ($1, $2)`)
+	if err != nil {
+		slog.Error("Error preparing query: %v", utils.Err(err))
+		return err
+	}
+	defer stmt.Close()
+
+	for _, code := range recoveryCodes {
+		if _, err := stmt.Exec(adminID, hashToken(normalizeRecoveryCode(code))); err != nil {
+			slog.Error("Error storing recovery code: %v", utils.Err(err))
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing MFA enrollment: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresMFARepository) DeleteMFA(adminID int32) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM admin_mfa WHERE admin_id = $1`, adminID)
+	if err != nil {
+		slog.Error("Error deleting MFA: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking deleted MFA: %v", utils.Err(err))
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrMFANotEnrolled
+	}
+
+	if _, err := tx.Exec(`DELETE FROM admin_recovery_codes WHERE admin_id = $1`, adminID); err != nil {
+		slog.Error("Error deleting recovery codes: %v", utils.Err(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing MFA reset: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// UseMFAStep records the time step of an accepted code. A code of the same or
+// an earlier step is rejected so that every code works only once.
+func (r *PostgresMFARepository) UseMFAStep(adminID int32, step int64) error {
+	query := `
+        UPDATE admin_mfa
+        SET last_used_step = $2
+        WHERE admin_id = $1 AND last_used_step < $2
+    `
+
+	result, err := r.DB.Exec(query, adminID, step)
+	if err != nil {
+		slog.Error("Error updating MFA step: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking MFA step: %v", utils.Err(err))
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (r *PostgresMFARepository) UseRecoveryCode(adminID int32, code string) error {
+	query := `
+        UPDATE admin_recovery_codes
+        SET used_at = CURRENT_TIMESTAMP
+        WHERE id = (
+            SELECT id
+            FROM admin_recovery_codes
+            WHERE admin_id = $1 AND code_hash = $2 AND used_at IS NULL
+            LIMIT 1
+        )
+    `
+
+	result, err := r.DB.Exec(query, adminID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		slog.Error("Error using recovery code: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		slog.Error("Error checking recovery code: %v", utils.Err(err))
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (r *PostgresMFARepository) IsMFARequiredForRole(role string) (bool, error) {
+	var required bool
+	err := r.DB.QueryRow(`SELECT required FROM role_mfa_requirements WHERE role = $1`, role).Scan(&required)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		slog.Error("Error getting role MFA requirement: %v", utils.Err(err))
+		return false, err
+	}
+
+	return required, nil
+}
+
+func (r *PostgresMFARepository) GetRoleMFARequirements() ([]domain.RoleMFARequirement, error) {
+	rows, err := r.DB.Query(`SELECT role, required FROM role_mfa_requirements ORDER BY role`)
+	if err != nil {
+		slog.Error("Error getting role MFA requirements: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	requirements := make([]domain.RoleMFARequirement, 0)
+	for rows.Next() {
+		var requirement domain.RoleMFARequirement
+		if err := rows.Scan(&requirement.Role, &requirement.Required); err != nil {
+			slog.Error("Error scanning role MFA requirement row: %v", utils.Err(err))
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over role MFA requirement rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return requirements, nil
+}
+
+func (r *PostgresMFARepository) SetRoleMFARequirement(requirement *domain.RoleMFARequirement) error {
+	query := `
+        INSERT INTO role_mfa_requirements (role, required)
+        VALUES ($1, $2)
+        ON CONFLICT (role) DO UPDATE
+        SET required = EXCLUDED.required,
+            updated_at = CURRENT_TIMESTAMP
+    `
+
+	_, err := r.DB.Exec(query, requirement.Role, requirement.Required)
+	if err != nil {
+		slog.Error("Error setting role MFA requirement: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
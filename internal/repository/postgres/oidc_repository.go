@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresOIDCRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresOIDCRepository(db *sql.DB) *PostgresOIDCRepository {
+	return &PostgresOIDCRepository{DB: db}
+}
+
+// CreateAuthRequest stores a pending sign-in and clears out the ones that were never completed
+func (r *PostgresOIDCRepository) CreateAuthRequest(request *domain.OIDCAuthRequest) error {
+	_, err := r.DB.Exec(`DELETE FROM oidc_auth_requests WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		slog.Error("Error deleting expired oidc auth requests: %v", utils.Err(err))
+		return err
+	}
+
+	_, err = r.DB.Exec(`
+		INSERT INTO oidc_auth_requests (state, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, request.State, request.Nonce, request.CodeVerifier, request.ExpiresAt)
+	if err != nil {
+		slog.Error("Error creating oidc auth request: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeAuthRequest returns the pending sign-in for the state exactly once
+func (r *PostgresOIDCRepository) ConsumeAuthRequest(state string) (*domain.OIDCAuthRequest, error) {
+	query := `
+        DELETE FROM oidc_auth_requests
+        WHERE state = $1 AND expires_at > CURRENT_TIMESTAMP
+        RETURNING state, nonce, code_verifier, expires_at
+    `
+
+	var request domain.OIDCAuthRequest
+	err := r.DB.QueryRow(query, state).Scan(&request.State, &request.Nonce, &request.CodeVerifier, &request.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidOIDCState
+		}
+
+		slog.Error("Error consuming oidc auth request: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *PostgresOIDCRepository) GetIdentityAdminID(issuer, subject string) (int32, error) {
+	var adminID int32
+	err := r.DB.QueryRow(`
+		SELECT admin_id FROM admin_identities WHERE issuer = $1 AND subject = $2
+	`, issuer, subject).Scan(&adminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, domain.ErrOIDCIdentityNotFound
+		}
+
+		slog.Error("Error getting admin identity: %v", utils.Err(err))
+		return 0, err
+	}
+
+	return adminID, nil
+}
+
+func (r *PostgresOIDCRepository) LinkIdentity(adminID int32, issuer, subject string) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO admin_identities (issuer, subject, admin_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (issuer, subject) DO NOTHING
+	`, issuer, subject, adminID)
+	if err != nil {
+		slog.Error("Error linking admin identity: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
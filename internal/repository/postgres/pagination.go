@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+// pageClause completes a listing with the keyset condition, order and limit of the requested page.
+// The conditions refer to queryParams by position. One row more than the page size is fetched
+// to tell whether more rows follow.
+func pageClause(conditions []string, queryParams []interface{}, terms []sortTerm, page domain.PageRequest) (string, []interface{}, error) {
+	if page.Cursor != nil {
+		condition, params, err := keysetCondition(terms, page.Cursor, queryParams)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		queryParams = params
+	}
+
+	clause := ` WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY ` + orderBy(terms)
+
+	queryParams = append(queryParams, page.PageSize+1)
+	clause += ` LIMIT $` + strconv.Itoa(len(queryParams))
+
+	if page.Cursor == nil {
+		queryParams = append(queryParams, (page.Page-1)*page.PageSize)
+		clause += ` OFFSET $` + strconv.Itoa(len(queryParams))
+	}
+
+	return clause, queryParams, nil
+}
+
+// keysetCondition matches the rows that come after the cursor in the order of the terms.
+// Terms can mix directions, so the condition spells out each position rather than comparing rows.
+func keysetCondition(terms []sortTerm, cursor *domain.Cursor, queryParams []interface{}) (string, []interface{}, error) {
+	if len(cursor.Values) != len(terms) {
+		return "", nil, domain.ErrInvalidCursor
+	}
+
+	placeholders := make([]string, len(terms))
+	for i, value := range cursor.Values {
+		queryParams = append(queryParams, value)
+		placeholders[i] = "$" + strconv.Itoa(len(queryParams))
+	}
+
+	alternatives := make([]string, 0, len(terms))
+	for i, term := range terms {
+		parts := make([]string, 0, i+1)
+		for j, previous := range terms[:i] {
+			parts = append(parts, previous.column+" = "+placeholders[j])
+		}
+
+		operator := " > "
+		if term.descending {
+			operator = " < "
+		}
+		parts = append(parts, term.column+operator+placeholders[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", queryParams, nil
+}
+
+// nextCursor points after the last row of a page, value returns the sort value of that row for a field
+func nextCursor(sort domain.Sort, terms []sortTerm, value func(field string) string) string {
+	values := make([]string, len(terms))
+	for i, term := range terms {
+		values[i] = value(term.field)
+	}
+
+	return domain.EncodeCursor(domain.Cursor{Sort: sort.String(), Values: values})
+}
+
+// countRows starts the page info of a listing with its total when the page asks for one.
+// Estimates come from the planner statistics and skip scanning the matching rows.
+func countRows(db *sql.DB, table string, conditions []string, queryParams []interface{}, mode string) (*domain.PageInfo, error) {
+	info := &domain.PageInfo{}
+	where := ` FROM ` + table + ` WHERE ` + strings.Join(conditions, " AND ")
+
+	switch mode {
+	case domain.TotalExact:
+		var total int64
+		if err := db.QueryRow(`SELECT COUNT(*)`+where, queryParams...).Scan(&total); err != nil {
+			slog.Error("Error counting rows: %v", utils.Err(err))
+			return nil, err
+		}
+		info.Total = &total
+
+	case domain.TotalEstimate:
+		var plan []byte
+		if err := db.QueryRow(`EXPLAIN (FORMAT JSON) SELECT 1`+where, queryParams...).Scan(&plan); err != nil {
+			slog.Error("Error estimating row count: %v", utils.Err(err))
+			return nil, err
+		}
+
+		var explained []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+		if err := json.Unmarshal(plan, &explained); err != nil {
+			slog.Error("Error reading query plan: %v", utils.Err(err))
+			return nil, err
+		}
+
+		var total int64
+		if len(explained) > 0 {
+			total = int64(explained[0].Plan.Rows)
+		}
+		info.Total = &total
+		info.TotalEstimated = true
+	}
+
+	return info, nil
+}
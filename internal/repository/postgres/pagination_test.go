@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"user-admin/internal/domain"
+)
+
+func TestPageClause(t *testing.T) {
+	terms := sortTerms(domain.Sort{{Field: "registration_date", Descending: true}}, userSortColumns)
+
+	tests := []struct {
+		name       string
+		page       domain.PageRequest
+		wantClause string
+		wantParams []interface{}
+	}{
+		{
+			name:       "page number",
+			page:       domain.PageRequest{Page: 3, PageSize: 10},
+			wantClause: ` WHERE deleted_at IS NULL AND blocked = $1 ORDER BY registration_date DESC, id LIMIT $2 OFFSET $3`,
+			wantParams: []interface{}{true, 11, 20},
+		},
+		{
+			name:       "cursor",
+			page:       domain.PageRequest{Page: 3, PageSize: 10, Cursor: &domain.Cursor{Values: []string{"2024-03-01T00:00:00Z", "42"}}},
+			wantClause: ` WHERE deleted_at IS NULL AND blocked = $1 AND ((registration_date < $2) OR (registration_date = $2 AND id > $3)) ORDER BY registration_date DESC, id LIMIT $4`,
+			wantParams: []interface{}{true, "2024-03-01T00:00:00Z", "42", 11},
+		},
+	}
+
+	for _, test := range tests {
+		clause, params, err := pageClause([]string{"deleted_at IS NULL", "blocked = $1"}, []interface{}{true}, terms, test.page)
+		if err != nil {
+			t.Fatalf("%s: pageClause: %v", test.name, err)
+		}
+		if clause != test.wantClause {
+			t.Errorf("%s: clause = %q, want %q", test.name, clause, test.wantClause)
+		}
+		if !reflect.DeepEqual(params, test.wantParams) {
+			t.Errorf("%s: params = %v, want %v", test.name, params, test.wantParams)
+		}
+	}
+}
+
+func TestPageClauseRejectsCursorOfOtherLength(t *testing.T) {
+	terms := sortTerms(nil, userSortColumns)
+	page := domain.PageRequest{Page: 1, PageSize: 10, Cursor: &domain.Cursor{Values: []string{"a", "42"}}}
+
+	if _, _, err := pageClause([]string{"deleted_at IS NULL"}, nil, terms, page); err != domain.ErrInvalidCursor {
+		t.Errorf("pageClause error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	terms := sortTerms(domain.DeletedUserSort, deletedUserSortColumns)
+	user := domain.DeletedUser{CommonUserResponse: domain.CommonUserResponse{ID: 42}}
+
+	encoded := nextCursor(domain.DeletedUserSort, terms, func(field string) string {
+		return deletedUserSortValue(user, field)
+	})
+
+	cursor, err := domain.DecodeCursor(encoded, domain.DeletedUserSort)
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if want := []string{"0001-01-01T00:00:00Z", "42"}; !reflect.DeepEqual(cursor.Values, want) {
+		t.Errorf("cursor values = %v, want %v", cursor.Values, want)
+	}
+}
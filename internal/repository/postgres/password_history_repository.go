@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresPasswordHistoryRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresPasswordHistoryRepository(db *sql.DB) *PostgresPasswordHistoryRepository {
+	return &PostgresPasswordHistoryRepository{DB: db}
+}
+
+// GetPasswordHistory returns the hashes of the admin's most recent passwords, newest first
+func (r *PostgresPasswordHistoryRepository) GetPasswordHistory(adminID int32, limit int) ([]string, error) {
+	query := `
+        SELECT password_hash
+        FROM admin_password_history
+        WHERE admin_id = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2
+    `
+
+	rows, err := r.DB.Query(query, adminID, limit)
+	if err != nil {
+		slog.Error("Error getting password history: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make([]string, 0, limit)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			slog.Error("Error scanning password history row: %v", utils.Err(err))
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over password history rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// recordPasswordHistory remembers a newly set password hash so that it cannot be reused
+func recordPasswordHistory(tx *sql.Tx, adminID int32, passwordHash string) error {
+	_, err := tx.Exec(`
+		INSERT INTO admin_password_history (admin_id, password_hash)
+		VALUES ($1, $2)
+	`, adminID, passwordHash)
+
+	return err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresPasswordResetRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresPasswordResetRepository(db *sql.DB) *PostgresPasswordResetRepository {
+	return &PostgresPasswordResetRepository{DB: db}
+}
+
+// CreatePasswordResetToken stores the hash of a new token, tokens requested earlier stop working
+func (r *PostgresPasswordResetRepository) CreatePasswordResetToken(adminID int32, token string, expiresAt time.Time) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE admin_password_reset_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE admin_id = $1 AND used_at IS NULL
+	`, adminID)
+	if err != nil {
+		slog.Error("Error invalidating password reset tokens: %v", utils.Err(err))
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO admin_password_reset_tokens (admin_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, adminID, hashToken(token), expiresAt)
+	if err != nil {
+		slog.Error("Error creating password reset token: %v", utils.Err(err))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPasswordResetAdminID returns the admin an unused and unexpired token belongs to
+func (r *PostgresPasswordResetRepository) GetPasswordResetAdminID(token string) (int32, error) {
+	query := `
+        SELECT admin_id
+        FROM admin_password_reset_tokens
+        WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+    `
+
+	var adminID int32
+	err := r.DB.QueryRow(query, hashToken(token)).Scan(&adminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, domain.ErrInvalidResetToken
+		}
+
+		slog.Error("Error getting password reset token: %v", utils.Err(err))
+		return 0, err
+	}
+
+	return adminID, nil
+}
+
+// UsePasswordResetToken marks the token used, it fails when another request got there first
+func (r *PostgresPasswordResetRepository) UsePasswordResetToken(token string) error {
+	result, err := r.DB.Exec(`
+		UPDATE admin_password_reset_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	`, hashToken(token))
+	if err != nil {
+		slog.Error("Error using password reset token: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrInvalidResetToken
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+
+	"github.com/lib/pq"
+)
+
+type PostgresRoleRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresRoleRepository(db *sql.DB) *PostgresRoleRepository {
+	return &PostgresRoleRepository{DB: db}
+}
+
+const roleQuery = `
+        SELECT r.name, r.description, r.builtin, r.created_at,
+            COALESCE(array_agg(p.permission ORDER BY p.permission) FILTER (WHERE p.permission IS NOT NULL), '{}')
+        FROM roles r
+        LEFT JOIN role_permissions p ON p.role = r.name
+    `
+
+func scanRole(row rowScanner) (*domain.Role, error) {
+	var role domain.Role
+
+	err := row.Scan(&role.Name, &role.Description, &role.Builtin, &role.CreatedAt, pq.Array(&role.Permissions))
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *PostgresRoleRepository) GetRoles() (*domain.RolesList, error) {
+	rows, err := r.DB.Query(roleQuery + ` GROUP BY r.name ORDER BY r.name`)
+	if err != nil {
+		slog.Error("Error getting roles: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := domain.RolesList{Roles: make([]domain.Role, 0)}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			slog.Error("Error scanning role row: %v", utils.Err(err))
+			return nil, err
+		}
+		list.Roles = append(list.Roles, *role)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over role rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (r *PostgresRoleRepository) GetRole(name string) (*domain.Role, error) {
+	role, err := scanRole(r.DB.QueryRow(roleQuery+` WHERE r.name = $1 GROUP BY r.name`, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRoleNotFound
+		}
+
+		slog.Error("Error getting role: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *PostgresRoleRepository) CreateRole(role *domain.Role) (*domain.Role, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO roles (name, description)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+	`, role.Name, role.Description)
+	if err != nil {
+		slog.Error("Error creating role: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, domain.ErrRoleAlreadyExists
+	}
+
+	if err := setRolePermissions(tx, role.Name, role.Permissions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return r.GetRole(role.Name)
+}
+
+// UpdateRole changes the description and replaces the permissions when they are given
+func (r *PostgresRoleRepository) UpdateRole(request *domain.UpdateRoleRequest) (*domain.Role, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		slog.Error("Error beginning transaction: %v", utils.Err(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRow(`SELECT name FROM roles WHERE name = $1 FOR UPDATE`, request.Name).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrRoleNotFound
+	}
+	if err != nil {
+		slog.Error("Error locking role: %v", utils.Err(err))
+		return nil, err
+	}
+
+	if request.Description != nil {
+		_, err := tx.Exec(`UPDATE roles SET description = $1 WHERE name = $2`, *request.Description, request.Name)
+		if err != nil {
+			slog.Error("Error updating role: %v", utils.Err(err))
+			return nil, err
+		}
+	}
+
+	if request.Permissions != nil {
+		if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role = $1`, request.Name); err != nil {
+			slog.Error("Error clearing role permissions: %v", utils.Err(err))
+			return nil, err
+		}
+
+		if err := setRolePermissions(tx, request.Name, request.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Error committing transaction: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return r.GetRole(request.Name)
+}
+
+// DeleteRole removes a role that no admin holds anymore
+func (r *PostgresRoleRepository) DeleteRole(name string) error {
+	var inUse bool
+	if err := r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM admins WHERE role = $1)`, name).Scan(&inUse); err != nil {
+		slog.Error("Error checking role usage: %v", utils.Err(err))
+		return err
+	}
+
+	if inUse {
+		return domain.ErrRoleInUse
+	}
+
+	result, err := r.DB.Exec(`DELETE FROM roles WHERE name = $1`, name)
+	if err != nil {
+		slog.Error("Error deleting role: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRoleRepository) GetRolePermissions(name string) ([]string, error) {
+	rows, err := r.DB.Query(`SELECT permission FROM role_permissions WHERE role = $1`, name)
+	if err != nil {
+		slog.Error("Error getting role permissions: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			slog.Error("Error scanning role permission row: %v", utils.Err(err))
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, rows.Err()
+}
+
+func setRolePermissions(tx *sql.Tx, role string, permissions []string) error {
+	_, err := tx.Exec(`
+		INSERT INTO role_permissions (role, permission)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING
+	`, role, pq.Array(permissions))
+	if err != nil {
+		slog.Error("Error setting role permissions: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+type PostgresSecurityEventRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresSecurityEventRepository(db *sql.DB) *PostgresSecurityEventRepository {
+	return &PostgresSecurityEventRepository{DB: db}
+}
+
+func (r *PostgresSecurityEventRepository) CreateSecurityEvent(event *domain.SecurityEvent) error {
+	details := event.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		slog.Error("Error encoding security event details: %v", utils.Err(err))
+		return err
+	}
+
+	query := `
+        INSERT INTO security_events (admin_id, event_type, ip_address, user_agent, details)
+        VALUES (NULLIF($1, 0), $2, $3, $4, $5)
+        RETURNING id, created_at
+    `
+
+	err = r.DB.QueryRow(
+		query,
+		event.AdminID,
+		event.EventType,
+		event.IPAddress,
+		event.UserAgent,
+		detailsJSON,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		slog.Error("Error creating security event: %v", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetSecurityEvents lists the most recent events first, optionally limited to one event type
+func (r *PostgresSecurityEventRepository) GetSecurityEvents(eventType string, page, pageSize int) (*domain.SecurityEventsList, error) {
+	offset := (page - 1) * pageSize
+
+	query := `
+        SELECT id, COALESCE(admin_id, 0), event_type, ip_address, user_agent, details, created_at
+        FROM security_events
+        WHERE $1 = '' OR event_type = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	rows, err := r.DB.QueryContext(context.TODO(), query, eventType, pageSize, offset)
+	if err != nil {
+		slog.Error("Error getting security events: %v", utils.Err(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	eventsList := domain.SecurityEventsList{Events: make([]domain.SecurityEvent, 0)}
+	for rows.Next() {
+		var event domain.SecurityEvent
+		var details []byte
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.AdminID,
+			&event.EventType,
+			&event.IPAddress,
+			&event.UserAgent,
+			&details,
+			&event.CreatedAt,
+		); err != nil {
+			slog.Error("Error scanning security event row: %v", utils.Err(err))
+			return nil, err
+		}
+
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			slog.Error("Error decoding security event details: %v", utils.Err(err))
+			return nil, err
+		}
+
+		eventsList.Events = append(eventsList.Events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over security event rows: %v", utils.Err(err))
+		return nil, err
+	}
+
+	return &eventsList, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"user-admin/internal/domain"
+)
+
+// Sort expressions of the sortable fields, they match the sort indexes
+var (
+	userSortColumns = map[string]string{
+		"id":                "id",
+		"first_name":        "COALESCE(first_name, '')",
+		"last_name":         "COALESCE(last_name, '')",
+		"phone_number":      "phone_number",
+		"location":          "COALESCE(location, '')",
+		"registration_date": "registration_date",
+	}
+
+	adminSortColumns = map[string]string{
+		"id":         "id",
+		"username":   "username",
+		"role":       "role",
+		"created_at": "created_at",
+	}
+
+	deletedUserSortColumns = map[string]string{
+		"id":         "id",
+		"deleted_at": "deleted_at",
+	}
+)
+
+// sortTerm is one expression of the effective order of a listing
+type sortTerm struct {
+	field      string
+	column     string
+	descending bool
+}
+
+// sortTerms resolves the sort into its effective order. The ID always comes last as a tiebreaker,
+// so rows that share a sort value keep the same order from page to page.
+// Fields are validated by domain.ParseSort, unknown ones are skipped rather than interpolated.
+func sortTerms(sort domain.Sort, columns map[string]string) []sortTerm {
+	var terms []sortTerm
+
+	for _, field := range sort {
+		column, ok := columns[field.Field]
+		if !ok {
+			continue
+		}
+
+		terms = append(terms, sortTerm{field: field.Field, column: column, descending: field.Descending})
+
+		if field.Field == "id" {
+			return terms
+		}
+	}
+
+	return append(terms, sortTerm{field: "id", column: "id"})
+}
+
+// orderBy builds the ORDER BY list of the terms
+func orderBy(terms []sortTerm) string {
+	order := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term.descending {
+			order = append(order, term.column+" DESC")
+		} else {
+			order = append(order, term.column)
+		}
+	}
+
+	return strings.Join(order, ", ")
+}
+
+// userSortValue returns the value a user is sorted by for the field, as it is kept in cursors
+func userSortValue(user domain.CommonUserResponse, field string) string {
+	switch field {
+	case "first_name":
+		return user.FirstName
+	case "last_name":
+		return user.LastName
+	case "phone_number":
+		return user.PhoneNumber
+	case "location":
+		return user.Location
+	case "registration_date":
+		return user.RegistrationDate.Format(time.RFC3339Nano)
+	default:
+		return strconv.Itoa(int(user.ID))
+	}
+}
+
+// deletedUserSortValue returns the value a user in the trash is sorted by for the field, as it is kept in cursors
+func deletedUserSortValue(user domain.DeletedUser, field string) string {
+	if field == "deleted_at" {
+		return user.DeletedAt.Format(time.RFC3339Nano)
+	}
+
+	return strconv.Itoa(int(user.ID))
+}
+
+// adminSortValue returns the value an admin is sorted by for the field, as it is kept in cursors
+func adminSortValue(admin domain.CommonAdminResponse, field string) string {
+	switch field {
+	case "username":
+		return admin.Username
+	case "role":
+		return admin.Role
+	case "created_at":
+		return admin.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return strconv.Itoa(int(admin.ID))
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"testing"
+	"time"
+	"user-admin/internal/domain"
+)
+
+func TestSortTerms(t *testing.T) {
+	tests := []struct {
+		sort domain.Sort
+		want string
+	}{
+		{nil, "id"},
+		{domain.Sort{{Field: "last_name"}}, "COALESCE(last_name, ''), id"},
+		{domain.Sort{{Field: "registration_date", Descending: true}, {Field: "first_name"}}, "registration_date DESC, COALESCE(first_name, ''), id"},
+		{domain.Sort{{Field: "id", Descending: true}, {Field: "last_name"}}, "id DESC"},
+		// Unknown fields never reach the query, even if they slipped past domain.ParseSort
+		{domain.Sort{{Field: "password"}, {Field: "phone_number"}}, "phone_number, id"},
+		{domain.Sort{{Field: "id; DROP TABLE users"}}, "id"},
+	}
+
+	for _, test := range tests {
+		if got := orderBy(sortTerms(test.sort, userSortColumns)); got != test.want {
+			t.Errorf("orderBy(sortTerms(%v)) = %q, want %q", test.sort, got, test.want)
+		}
+	}
+}
+
+func TestSortValues(t *testing.T) {
+	registered := time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	user := domain.CommonUserResponse{ID: 42, LastName: "Myradow", RegistrationDate: registered}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"id", "42"},
+		{"last_name", "Myradow"},
+		{"first_name", ""},
+		{"registration_date", "2024-03-01T12:30:00.123456Z"},
+	}
+
+	for _, test := range tests {
+		if got := userSortValue(user, test.field); got != test.want {
+			t.Errorf("userSortValue(%s) = %q, want %q", test.field, got, test.want)
+		}
+	}
+
+	admin := domain.CommonAdminResponse{ID: 7, Username: "jdoe", Role: "admin", CreatedAt: registered}
+	if got := adminSortValue(admin, "created_at"); got != "2024-03-01T12:30:00.123456Z" {
+		t.Errorf("adminSortValue(created_at) = %q", got)
+	}
+	if got := adminSortValue(admin, "id"); got != "7" {
+		t.Errorf("adminSortValue(id) = %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 	"user-admin/internal/domain"
 	"user-admin/pkg/lib/utils"
 )
@@ -20,84 +21,138 @@ func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
 	return &PostgresUserRepository{DB: db}
 }
 
-func (r *PostgresUserRepository) GetAllUsers(page, pageSize int) (*domain.UsersList, error) {
-	offset := (page - 1) * pageSize
+func (r *PostgresUserRepository) GetAllUsers(filter domain.UserFilter, sort domain.Sort, page domain.PageRequest) (*domain.UsersList, *domain.PageInfo, error) {
+	conditions, queryParams := userFilterConditions(filter, nil)
+
+	return r.listUsers(conditions, queryParams, sort, page)
+}
+
+// listUsers runs a paged user listing limited by the conditions, which refer to queryParams by position
+func (r *PostgresUserRepository) listUsers(conditions []string, queryParams []interface{}, sort domain.Sort, page domain.PageRequest) (*domain.UsersList, *domain.PageInfo, error) {
+	info, err := countRows(r.DB, "users", conditions, queryParams, page.Total)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms := sortTerms(sort, userSortColumns)
+
+	clause, queryParams, err := pageClause(conditions, queryParams, terms, page)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	query := `
         SELECT id, first_name, last_name, phone_number, blocked,
         registration_date, gender, date_of_birth, location,
         email, profile_photo_url
-        FROM users
-        ORDER BY id
-        LIMIT $1 OFFSET $2
-    `
+        FROM users` + clause
+
 	stmt, err := r.DB.Prepare(query)
 	if err != nil {
 		slog.Error("Error preparing query: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.TODO(), pageSize, offset)
+	rows, err := stmt.QueryContext(context.TODO(), queryParams...)
 	if err != nil {
 		slog.Error("Error executing query: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var usersList domain.UsersList
-
-	var firstName, lastName, gender, location, email, profilePhotoURL sql.NullString
-	var dateOfBirth sql.NullTime
-
+	usersList := domain.UsersList{Users: make([]domain.CommonUserResponse, 0)}
 	for rows.Next() {
-		var user domain.CommonUserResponse
-		if err := rows.Scan(
-			&user.ID,
-			&firstName,
-			&lastName,
-			&user.PhoneNumber,
-			&user.Blocked,
-			&user.RegistrationDate,
-			&gender,
-			&dateOfBirth,
-			&location,
-			&email,
-			&profilePhotoURL,
-		); err != nil {
-			slog.Error("Error scanning user row: %v", utils.Err(err))
-			return nil, err
-		}
-
-		user.FirstName = utils.HandleNullString(firstName)
-		user.LastName = utils.HandleNullString(lastName)
-		user.Gender = utils.HandleNullString(gender)
-		user.Location = utils.HandleNullString(location)
-		user.Email = utils.HandleNullString(email)
-		user.ProfilePhotoURL = utils.HandleNullString(profilePhotoURL)
-
-		if dateOfBirth.Valid {
-			user.DateOfBirth.Year = int32(dateOfBirth.Time.Year())
-			user.DateOfBirth.Month = int32(dateOfBirth.Time.Month())
-			user.DateOfBirth.Day = int32(dateOfBirth.Time.Day())
+		user, err := utils.ScanUserRow(rows)
+		if err != nil {
+			return nil, nil, err
 		}
-
 		usersList.Users = append(usersList.Users, user)
 	}
 
 	if err := rows.Err(); err != nil {
 		slog.Error("Error iterating over user rows: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
+	}
+
+	if len(usersList.Users) > page.PageSize {
+		usersList.Users = usersList.Users[:page.PageSize]
+		last := usersList.Users[page.PageSize-1]
+
+		info.HasMore = true
+		info.NextCursor = nextCursor(sort, terms, func(field string) string {
+			return userSortValue(last, field)
+		})
 	}
 
-	return &usersList, nil
+	return &usersList, info, nil
+}
+
+// userFilterConditions turns the filter into WHERE conditions whose values are appended to queryParams.
+// Deleted users are always left out.
+func userFilterConditions(filter domain.UserFilter, queryParams []interface{}) ([]string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+
+	// param adds a value to queryParams and returns its placeholder
+	param := func(value interface{}) string {
+		queryParams = append(queryParams, value)
+		return "$" + strconv.Itoa(len(queryParams))
+	}
+
+	if filter.Blocked != nil {
+		conditions = append(conditions, "blocked = "+param(*filter.Blocked))
+	}
+
+	if filter.Gender != "" {
+		conditions = append(conditions, "LOWER(gender) = LOWER("+param(filter.Gender)+")")
+	}
+
+	if filter.Location != "" {
+		conditions = append(conditions, "LOWER(location) = LOWER("+param(filter.Location)+")")
+	}
+
+	if filter.RegisteredFrom != nil {
+		conditions = append(conditions, "registration_date >= "+param(*filter.RegisteredFrom))
+	}
+
+	if filter.RegisteredTo != nil {
+		conditions = append(conditions, "registration_date < "+param(filter.RegisteredTo.AddDate(0, 0, 1)))
+	}
+
+	// Someone is at least MinAge when they were born on or before that many years ago,
+	// and at most MaxAge when they were born after MaxAge+1 years ago
+	if filter.MinAge != nil {
+		conditions = append(conditions, "date_of_birth <= CURRENT_DATE - make_interval(years => "+param(*filter.MinAge)+")")
+	}
+
+	if filter.MaxAge != nil {
+		conditions = append(conditions, "date_of_birth > CURRENT_DATE - make_interval(years => "+param(*filter.MaxAge+1)+")")
+	}
+
+	if filter.HasEmail != nil {
+		if *filter.HasEmail {
+			conditions = append(conditions, "COALESCE(email, '') <> ''")
+		} else {
+			conditions = append(conditions, "COALESCE(email, '') = ''")
+		}
+	}
+
+	if filter.HasPhoto != nil {
+		if *filter.HasPhoto {
+			conditions = append(conditions, "COALESCE(profile_photo_url, '') <> ''")
+		} else {
+			conditions = append(conditions, "COALESCE(profile_photo_url, '') = ''")
+		}
+	}
+
+	return conditions, queryParams
 }
 
 func (r *PostgresUserRepository) GetUserByID(id int32) (*domain.GetUserResponse, error) {
 	stmt, err := r.DB.Prepare(`
 		SELECT id, first_name, last_name, phone_number, blocked, registration_date, gender, date_of_birth, location, email, profile_photo_url
 		FROM users 
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`)
 
 	if err != nil {
@@ -124,6 +179,9 @@ func (r *PostgresUserRepository) GetUserByID(id int32) (*domain.GetUserResponse,
 		&email,
 		&profilePhotoURL,
 	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrUserNotFound
+	}
 	if err != nil {
 		slog.Error("error scanning user row: %v", utils.Err(err))
 		return nil, err
@@ -277,7 +335,7 @@ func (r PostgresUserRepository) UpdateUser(request *domain.UpdateUserRequest) (*
 		queryParams = append(queryParams, request.ProfilePhotoURL)
 	}
 
-	updateQuery += " " + strings.Join(queryArgs, ", ") + " WHERE id = $" + strconv.Itoa(len(queryParams)+1)
+	updateQuery += " " + strings.Join(queryArgs, ", ") + " WHERE id = $" + strconv.Itoa(len(queryParams)+1) + " AND deleted_at IS NULL"
 	queryParams = append(queryParams, request.ID)
 
 	updateQuery += " RETURNING id, first_name, last_name, phone_number, blocked, gender, registration_date, date_of_birth, location, email, profile_photo_url"
@@ -306,6 +364,9 @@ func (r PostgresUserRepository) UpdateUser(request *domain.UpdateUserRequest) (*
 		&email,
 		&profilePhotoURL,
 	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrUserNotFound
+	}
 	if err != nil {
 		slog.Error("error executing  query: %v", utils.Err(err))
 		return nil, err
@@ -327,35 +388,175 @@ func (r PostgresUserRepository) UpdateUser(request *domain.UpdateUserRequest) (*
 	return &user, nil
 }
 
-func (r PostgresUserRepository) DeleteUser(id int32) error {
-	var exists bool
-	err := r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists)
+// DeleteUser moves the user to the trash, where it stays until it is restored or purged
+func (r PostgresUserRepository) DeleteUser(id, deletedBy int32) error {
+	result, err := r.DB.Exec(`
+		UPDATE users
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF($2, 0)
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedBy)
 	if err != nil {
-		slog.Error("error checking user existence: %v", utils.Err(err))
+		slog.Error("error deleting user: %v", utils.Err(err))
 		return err
 	}
 
-	if !exists {
-		return fmt.Errorf("user with ID %d not found", id)
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrUserNotFound
 	}
 
-	stmt, err := r.DB.Prepare(`DELETE FROM users WHERE id = $1`)
+	return nil
+}
+
+// RestoreUser takes the user out of the trash
+func (r *PostgresUserRepository) RestoreUser(id int32) error {
+	result, err := r.DB.Exec(`
+		UPDATE users
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
 	if err != nil {
-		slog.Error("error preparing query: %v", err)
+		slog.Error("error restoring user: %v", utils.Err(err))
 		return err
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
+	affected, err := result.RowsAffected()
 	if err != nil {
-		slog.Error("error executing query: %v", utils.Err(err))
 		return err
 	}
 
+	if affected == 0 {
+		return r.notDeletedError(id)
+	}
+
 	return nil
 }
 
-func (r *PostgresUserRepository) BlockUser(id int32) error {
+// GetDeletedUsers lists the trash in the order of domain.DeletedUserSort
+func (r *PostgresUserRepository) GetDeletedUsers(page domain.PageRequest) (*domain.DeletedUsersList, *domain.PageInfo, error) {
+	conditions := []string{"deleted_at IS NOT NULL"}
+
+	info, err := countRows(r.DB, "users", conditions, nil, page.Total)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms := sortTerms(domain.DeletedUserSort, deletedUserSortColumns)
+
+	clause, queryParams, err := pageClause(conditions, nil, terms, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT id, first_name, last_name, phone_number, blocked,
+		registration_date, gender, date_of_birth, location,
+		email, profile_photo_url, deleted_at, deleted_by
+		FROM users`+clause, queryParams...)
+	if err != nil {
+		slog.Error("Error getting deleted users: %v", utils.Err(err))
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	list := domain.DeletedUsersList{Users: make([]domain.DeletedUser, 0)}
+	for rows.Next() {
+		var user domain.DeletedUser
+		var firstName, lastName, gender, location, email, profilePhotoURL sql.NullString
+		var dateOfBirth sql.NullTime
+		var deletedBy sql.NullInt32
+
+		if err := rows.Scan(
+			&user.ID,
+			&firstName,
+			&lastName,
+			&user.PhoneNumber,
+			&user.Blocked,
+			&user.RegistrationDate,
+			&gender,
+			&dateOfBirth,
+			&location,
+			&email,
+			&profilePhotoURL,
+			&user.DeletedAt,
+			&deletedBy,
+		); err != nil {
+			slog.Error("Error scanning deleted user row: %v", utils.Err(err))
+			return nil, nil, err
+		}
+
+		user.FirstName = utils.HandleNullString(firstName)
+		user.LastName = utils.HandleNullString(lastName)
+		user.Gender = utils.HandleNullString(gender)
+		user.Location = utils.HandleNullString(location)
+		user.Email = utils.HandleNullString(email)
+		user.ProfilePhotoURL = utils.HandleNullString(profilePhotoURL)
+		user.DeletedBy = deletedBy.Int32
+
+		if dateOfBirth.Valid {
+			user.DateOfBirth.Year = int32(dateOfBirth.Time.Year())
+			user.DateOfBirth.Month = int32(dateOfBirth.Time.Month())
+			user.DateOfBirth.Day = int32(dateOfBirth.Time.Day())
+		}
+
+		list.Users = append(list.Users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Error iterating over deleted user rows: %v", utils.Err(err))
+		return nil, nil, err
+	}
+
+	if len(list.Users) > page.PageSize {
+		list.Users = list.Users[:page.PageSize]
+		last := list.Users[page.PageSize-1]
+
+		info.HasMore = true
+		info.NextCursor = nextCursor(domain.DeletedUserSort, terms, func(field string) string {
+			return deletedUserSortValue(last, field)
+		})
+	}
+
+	return &list, info, nil
+}
+
+// PurgeUser permanently deletes a user, which has to be in the trash already
+func (r *PostgresUserRepository) PurgeUser(id int32) error {
+	result, err := r.DB.Exec(`DELETE FROM users WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		slog.Error("error purging user: %v", utils.Err(err))
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return r.notDeletedError(id)
+	}
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently deletes the users that were moved to the trash before the given time
+func (r *PostgresUserRepository) PurgeDeletedUsers(before time.Time) (int64, error) {
+	result, err := r.DB.Exec(`DELETE FROM users WHERE deleted_at < $1`, before)
+	if err != nil {
+		slog.Error("error purging deleted users: %v", utils.Err(err))
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// notDeletedError tells a user that does not exist apart from one that is not in the trash
+func (r *PostgresUserRepository) notDeletedError(id int32) error {
 	var exists bool
 	err := r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists)
 	if err != nil {
@@ -363,6 +564,21 @@ func (r *PostgresUserRepository) BlockUser(id int32) error {
 		return err
 	}
 
+	if exists {
+		return domain.ErrUserNotDeleted
+	}
+
+	return domain.ErrUserNotFound
+}
+
+func (r *PostgresUserRepository) BlockUser(id int32) error {
+	var exists bool
+	err := r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		slog.Error("error checking user existence: %v", utils.Err(err))
+		return err
+	}
+
 	if !exists {
 		return fmt.Errorf("user with ID %d not found", id)
 	}
@@ -384,7 +600,7 @@ func (r *PostgresUserRepository) BlockUser(id int32) error {
 }
 
 func (r *PostgresUserRepository) UnblockUser(id int32) error {
-	stmt, err := r.DB.Prepare("UPDATE users SET blocked = false WHERE id = $1")
+	stmt, err := r.DB.Prepare("UPDATE users SET blocked = false WHERE id = $1 AND deleted_at IS NULL")
 	if err != nil {
 		slog.Error("error preparing query: %v", utils.Err(err))
 		return err
@@ -400,46 +616,157 @@ func (r *PostgresUserRepository) UnblockUser(id int32) error {
 	return nil
 }
 
-func (r *PostgresUserRepository) SearchUsers(query string, page, pageSize int) (*domain.UsersList, error) {
-	offset := (page - 1) * pageSize
+// SearchUsers ranks the users that match every term of the search. Unqualified terms match the search vector
+// by prefix and names and email by trigram similarity, which tolerates typos. Phone terms match the digits only.
+func (r *PostgresUserRepository) SearchUsers(search domain.UserSearchQuery, filter domain.UserFilter, sort domain.Sort, page domain.PageRequest) (*domain.UserSearchResults, *domain.PageInfo, error) {
+	conditions, queryParams := userFilterConditions(filter, nil)
+
+	var ranks []string
+	for _, term := range search.Terms {
+		var condition, rank string
+		condition, rank, queryParams = searchTermCondition(term, queryParams)
+
+		conditions = append(conditions, condition)
+		if rank != "" {
+			ranks = append(ranks, rank)
+		}
+	}
+
+	rank := "0::float8"
+	if len(ranks) > 0 {
+		rank = "(" + strings.Join(ranks, " + ") + ")::float8"
+	}
+
+	info, err := countRows(r.DB, "users", conditions, queryParams, page.Total)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]string{"relevance": rank}
+	for field, column := range userSortColumns {
+		columns[field] = column
+	}
+	terms := sortTerms(sort, columns)
+
+	clause, queryParams, err := pageClause(conditions, queryParams, terms, page)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	searchQuery := `
         SELECT id, first_name, last_name, phone_number, blocked,
         registration_date, gender, date_of_birth, location,
-        email, profile_photo_url
-        FROM users
-        WHERE first_name ILIKE $1 OR last_name ILIKE $1 OR phone_number ILIKE $1 OR email ILIKE $1
-        ORDER BY id
-        LIMIT $2 OFFSET $3
-    `
+        email, profile_photo_url, ` + rank + `
+        FROM users` + clause
 
 	stmt, err := r.DB.Prepare(searchQuery)
 	if err != nil {
 		slog.Error("Error preparing search query: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(context.TODO(), "%"+query+"%", pageSize, offset)
+	rows, err := stmt.QueryContext(context.TODO(), queryParams...)
 	if err != nil {
 		slog.Error("Error executing search query: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	userList := domain.UsersList{Users: make([]domain.CommonUserResponse, 0)}
+	results := domain.UserSearchResults{Users: make([]domain.UserSearchResult, 0)}
 	for rows.Next() {
-		user, err := utils.ScanUserRow(rows)
+		var result domain.UserSearchResult
+
+		result.CommonUserResponse, err = utils.ScanUserRow(rows, &result.Rank)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		userList.Users = append(userList.Users, user)
+		results.Users = append(results.Users, result)
 	}
 
 	if err := rows.Err(); err != nil {
 		slog.Error("Error iterating over user rows: %v", utils.Err(err))
-		return nil, err
+		return nil, nil, err
+	}
+
+	if len(results.Users) > page.PageSize {
+		results.Users = results.Users[:page.PageSize]
+		last := results.Users[page.PageSize-1]
+
+		info.HasMore = true
+		info.NextCursor = nextCursor(sort, terms, func(field string) string {
+			if field == "relevance" {
+				return strconv.FormatFloat(last.Rank, 'g', -1, 64)
+			}
+			return userSortValue(last.CommonUserResponse, field)
+		})
 	}
 
-	return &userList, nil
+	return &results, info, nil
+}
+
+// searchTermCondition returns the condition a user has to meet to match the term and the expression
+// the term adds to the rank, or an empty one. The values of both are appended to queryParams.
+func searchTermCondition(term domain.SearchTerm, queryParams []interface{}) (string, string, []interface{}) {
+	param := func(value interface{}) string {
+		queryParams = append(queryParams, value)
+		return "$" + strconv.Itoa(len(queryParams))
+	}
+
+	switch term.Field {
+	case "phone":
+		digits := param(likePattern(domain.Digits(term.Value)))
+		return "phone_digits LIKE " + digits, "", queryParams
+
+	case "name":
+		value, pattern := param(term.Value), param(likePattern(term.Value))
+		condition := "(first_name ILIKE " + pattern + " OR " + value + " <% first_name" +
+			" OR last_name ILIKE " + pattern + " OR " + value + " <% last_name)"
+		rank := "COALESCE(GREATEST(word_similarity(" + value + ", first_name), word_similarity(" + value + ", last_name)), 0)"
+		return condition, rank, queryParams
+
+	case "first_name", "last_name", "email", "location":
+		value, pattern := param(term.Value), param(likePattern(term.Value))
+		condition := "(" + term.Field + " ILIKE " + pattern + " OR " + value + " <% " + term.Field + ")"
+		rank := "COALESCE(word_similarity(" + value + ", " + term.Field + "), 0)"
+		return condition, rank, queryParams
+	}
+
+	value := param(term.Value)
+	alternatives := []string{value + " <% first_name", value + " <% last_name", value + " <% email"}
+	ranks := []string{"COALESCE(GREATEST(word_similarity(" + value + ", first_name), word_similarity(" + value + ", last_name), word_similarity(" + value + ", email)), 0)"}
+
+	if prefixes := prefixQuery(term.Value); prefixes != "" {
+		query := "to_tsquery('simple', " + param(prefixes) + ")"
+		alternatives = append(alternatives, "search_vector @@ "+query)
+		ranks = append(ranks, "ts_rank(search_vector, "+query+")")
+	}
+
+	if digits := domain.Digits(term.Value); digits != "" {
+		pattern := param(likePattern(digits))
+		alternatives = append(alternatives, "phone_digits LIKE "+pattern)
+		ranks = append(ranks, "(phone_digits LIKE "+pattern+")::int")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", strings.Join(ranks, " + "), queryParams
+}
+
+// prefixQuery turns the words of a term into a tsquery that matches words starting with each of them.
+// Only letters and digits are kept, so nothing of the term is read as tsquery syntax.
+func prefixQuery(value string) string {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// likePattern matches the value anywhere, with the LIKE wildcards in it taken literally
+func likePattern(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	return "%" + value + "%"
 }
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"user-admin/internal/domain"
+)
+
+func TestUserFilterConditionsDateBounds(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	conditions, params := userFilterConditions(domain.UserFilter{RegisteredFrom: &from, RegisteredTo: &to}, []interface{}{"search"})
+
+	want := []string{"deleted_at IS NULL", "registration_date >= $2", "registration_date < $3"}
+	if strings.Join(conditions, " AND ") != strings.Join(want, " AND ") {
+		t.Errorf("conditions = %q, want %q", conditions, want)
+	}
+
+	if len(params) != 3 || params[0] != "search" {
+		t.Fatalf("params = %v, want the search parameter followed by both dates", params)
+	}
+	if params[1] != from {
+		t.Errorf("lower bound = %v, want %v", params[1], from)
+	}
+	// The last day is included, so the bound is the start of the next day
+	if want := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC); params[2] != want {
+		t.Errorf("upper bound = %v, want %v", params[2], want)
+	}
+}
+
+func TestUserFilterConditionsAges(t *testing.T) {
+	minAge, maxAge := 18, 30
+
+	conditions, params := userFilterConditions(domain.UserFilter{MinAge: &minAge, MaxAge: &maxAge}, nil)
+
+	if len(conditions) != 3 || len(params) != 2 {
+		t.Fatalf("conditions = %q, params = %v", conditions, params)
+	}
+	if params[0] != 18 {
+		t.Errorf("minimum age parameter = %v, want 18", params[0])
+	}
+	// Someone turns 31 on the day they stop being at most 30
+	if params[1] != 31 {
+		t.Errorf("maximum age parameter = %v, want 31", params[1])
+	}
+}
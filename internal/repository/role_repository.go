@@ -0,0 +1,12 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type RoleRepository interface {
+	GetRoles() (*domain.RolesList, error)
+	GetRole(name string) (*domain.Role, error)
+	CreateRole(role *domain.Role) (*domain.Role, error)
+	UpdateRole(request *domain.UpdateRoleRequest) (*domain.Role, error)
+	DeleteRole(name string) error
+	GetRolePermissions(name string) ([]string, error)
+}
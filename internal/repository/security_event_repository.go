@@ -0,0 +1,8 @@
+package repository
+
+import "user-admin/internal/domain"
+
+type SecurityEventRepository interface {
+	CreateSecurityEvent(event *domain.SecurityEvent) error
+	GetSecurityEvents(eventType string, page, pageSize int) (*domain.SecurityEventsList, error)
+}
@@ -1,14 +1,21 @@
 package repository
 
-import "user-admin/internal/domain"
+import (
+	"time"
+	"user-admin/internal/domain"
+)
 
 type UserRepository interface {
-	GetAllUsers(page, pageSize int) (*domain.UsersList, error)
+	GetAllUsers(filter domain.UserFilter, sort domain.Sort, page domain.PageRequest) (*domain.UsersList, *domain.PageInfo, error)
 	GetUserByID(id int32) (*domain.GetUserResponse, error)
 	CreateUser(request *domain.CreateUserRequest) (*domain.CreateUserResponse, error)
 	UpdateUser(request *domain.UpdateUserRequest) (*domain.UpdateUserResponse, error)
-	DeleteUser(id int32) error
+	DeleteUser(id, deletedBy int32) error
+	RestoreUser(id int32) error
+	GetDeletedUsers(page domain.PageRequest) (*domain.DeletedUsersList, *domain.PageInfo, error)
+	PurgeUser(id int32) error
+	PurgeDeletedUsers(before time.Time) (int64, error)
 	BlockUser(id int32) error
 	UnblockUser(id int32) error
-	SearchUsers(query string, page, pageSize int) (*domain.UsersList, error)
+	SearchUsers(search domain.UserSearchQuery, filter domain.UserFilter, sort domain.Sort, page domain.PageRequest) (*domain.UserSearchResults, *domain.PageInfo, error)
 }
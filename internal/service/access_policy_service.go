@@ -0,0 +1,186 @@
+package service
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
+)
+
+type AccessPolicyService struct {
+	AccessPolicyRepository  repository.AccessPolicyRepository
+	AdminRepository         repository.AdminRepository
+	RoleRepository          repository.RoleRepository
+	SecurityEventRepository repository.SecurityEventRepository
+	// Location is the timezone the weekdays and hours of every policy are read in
+	Location *time.Location
+}
+
+func NewAccessPolicyService(
+	accessPolicyRepository repository.AccessPolicyRepository,
+	adminRepository repository.AdminRepository,
+	roleRepository repository.RoleRepository,
+	securityEventRepository repository.SecurityEventRepository,
+	location *time.Location,
+) *AccessPolicyService {
+	return &AccessPolicyService{
+		AccessPolicyRepository:  accessPolicyRepository,
+		AdminRepository:         adminRepository,
+		RoleRepository:          roleRepository,
+		SecurityEventRepository: securityEventRepository,
+		Location:                location,
+	}
+}
+
+func (s *AccessPolicyService) GetAccessPolicies() (*domain.AccessPoliciesList, error) {
+	return s.AccessPolicyRepository.GetAccessPolicies()
+}
+
+func (s *AccessPolicyService) CreateAccessPolicy(principal *domain.Principal, request *domain.AccessPolicyRequest) (*domain.AccessPolicy, error) {
+	if err := s.validate(principal, request); err != nil {
+		return nil, err
+	}
+
+	return s.AccessPolicyRepository.CreateAccessPolicy(request)
+}
+
+func (s *AccessPolicyService) UpdateAccessPolicy(principal *domain.Principal, request *domain.AccessPolicyRequest) (*domain.AccessPolicy, error) {
+	if err := s.validate(principal, request); err != nil {
+		return nil, err
+	}
+
+	return s.AccessPolicyRepository.UpdateAccessPolicy(request)
+}
+
+func (s *AccessPolicyService) DeleteAccessPolicy(id int32) error {
+	return s.AccessPolicyRepository.DeleteAccessPolicy(id)
+}
+
+// CheckAccess returns domain.ErrAccessDenied unless one of the policies that apply to the admin
+// allows the client right now. Admins without any policy are not restricted.
+func (s *AccessPolicyService) CheckAccess(adminID int32, client domain.ClientInfo) error {
+	policies, err := s.AccessPolicyRepository.GetAccessPoliciesForAdmin(adminID)
+	if err != nil {
+		slog.Error("Error getting access policies:", utils.Err(err))
+		return err
+	}
+
+	if len(policies) == 0 {
+		return nil
+	}
+
+	ip := net.ParseIP(client.IPAddress)
+	now := time.Now().In(s.Location)
+
+	for i := range policies {
+		if policies[i].Allows(ip, now) {
+			return nil
+		}
+	}
+
+	err = s.SecurityEventRepository.CreateSecurityEvent(&domain.SecurityEvent{
+		AdminID:   adminID,
+		EventType: domain.SecurityEventAccessPolicyDenied,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"local_time": now.Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		slog.Error("Error recording security event:", utils.Err(err))
+	}
+
+	return domain.ErrAccessDenied
+}
+
+// validate checks that the policy targets exactly one role or admin and normalizes its networks.
+// Super admins cannot be restricted, neither as a role nor one by one, so nobody can lock
+// everyone out, and the caller cannot restrict roles or admins that outrank them.
+func (s *AccessPolicyService) validate(principal *domain.Principal, request *domain.AccessPolicyRequest) error {
+	if (request.Role == "") == (request.AdminID == 0) {
+		return domain.ErrInvalidAccessPolicy
+	}
+
+	role := request.Role
+	if role != "" {
+		if _, err := s.RoleRepository.GetRole(role); err != nil {
+			return err
+		}
+	} else {
+		admin, err := s.AdminRepository.GetAdminByID(request.AdminID)
+		if err != nil {
+			return err
+		}
+		role = admin.Role
+	}
+
+	if role == domain.SuperAdminRole {
+		return domain.ErrRoleProtected
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, role); err != nil {
+		return err
+	}
+
+	cidrs := make([]string, 0, len(request.CIDRs))
+	for _, cidr := range request.CIDRs {
+		normalized, ok := normalizeCIDR(cidr)
+		if !ok {
+			return domain.ErrInvalidAccessPolicy
+		}
+		cidrs = append(cidrs, normalized)
+	}
+	request.CIDRs = cidrs
+
+	for _, weekday := range request.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return domain.ErrInvalidAccessPolicy
+		}
+	}
+
+	if (request.StartHour == nil) != (request.EndHour == nil) {
+		return domain.ErrInvalidAccessPolicy
+	}
+
+	if request.StartHour != nil {
+		start, end := *request.StartHour, *request.EndHour
+		if start < 0 || start > 23 || end < 1 || end > 24 || start == end {
+			return domain.ErrInvalidAccessPolicy
+		}
+	}
+
+	// A policy without any restriction would silently lift the others
+	if len(request.CIDRs) == 0 && len(request.Weekdays) == 0 && request.StartHour == nil {
+		return domain.ErrInvalidAccessPolicy
+	}
+
+	return nil
+}
+
+// normalizeCIDR accepts a network or a single address, which becomes a network of one
+func normalizeCIDR(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", false
+		}
+
+		if ip.To4() != nil {
+			return ip.String() + "/32", true
+		}
+		return ip.String() + "/128", true
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return "", false
+	}
+
+	return network.String(), true
+}
@@ -0,0 +1,30 @@
+package service
+
+import "testing"
+
+func TestNormalizeCIDR(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8", true},
+		{" 10.1.2.3/8 ", "10.0.0.0/8", true},
+		{"192.168.1.10", "192.168.1.10/32", true},
+		{"2001:db8::1", "2001:db8::1/128", true},
+		{"2001:DB8:0:0::/32", "2001:db8::/32", true},
+		{"::ffff:10.1.2.3", "10.1.2.3/32", true},
+		{"10.0.0.0/33", "", false},
+		{"2001:db8::/129", "", false},
+		{"10.0.0", "", false},
+		{"example.com", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := normalizeCIDR(test.value)
+		if ok != test.wantOK || got != test.want {
+			t.Errorf("normalizeCIDR(%q) = (%q, %v), want (%q, %v)", test.value, got, ok, test.want, test.wantOK)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"user-admin/internal/config"
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
+)
+
+type AccessReviewService struct {
+	AccessReviewRepository  repository.AccessReviewRepository
+	AdminRepository         repository.AdminRepository
+	RoleRepository          repository.RoleRepository
+	SecurityEventRepository repository.SecurityEventRepository
+	Config                  config.AccessReview
+}
+
+func NewAccessReviewService(
+	accessReviewRepository repository.AccessReviewRepository,
+	adminRepository repository.AdminRepository,
+	roleRepository repository.RoleRepository,
+	securityEventRepository repository.SecurityEventRepository,
+	cfg config.AccessReview,
+) *AccessReviewService {
+	return &AccessReviewService{
+		AccessReviewRepository:  accessReviewRepository,
+		AdminRepository:         adminRepository,
+		RoleRepository:          roleRepository,
+		SecurityEventRepository: securityEventRepository,
+		Config:                  cfg,
+	}
+}
+
+func (s *AccessReviewService) GetAccessReviews() (*domain.AccessReviewsList, error) {
+	return s.AccessReviewRepository.GetAccessReviews()
+}
+
+func (s *AccessReviewService) GetAccessReview(id int32) (*domain.AccessReview, error) {
+	return s.AccessReviewRepository.GetAccessReview(id)
+}
+
+// CreateAccessReview opens a campaign covering every admin as they are right now
+func (s *AccessReviewService) CreateAccessReview(name string, createdBy int32, client domain.ClientInfo) (*domain.AccessReview, error) {
+	review, err := s.AccessReviewRepository.CreateAccessReview(strings.TrimSpace(name), createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   createdBy,
+		EventType: domain.SecurityEventAccessReviewOpened,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"access_review_id": review.ID,
+			"admins":           review.Total,
+		},
+	})
+
+	return review, nil
+}
+
+// ConfirmAdmin recertifies the admin's access
+func (s *AccessReviewService) ConfirmAdmin(reviewID, adminID, decidedBy int32, comment string) error {
+	return s.AccessReviewRepository.DecideAccessReviewItem(reviewID, adminID, decidedBy, domain.AccessReviewConfirmed, comment)
+}
+
+// RevokeAdmin disables an admin the caller outranks, who keeps their history and can be
+// enabled again by an admin holding admins:enable
+func (s *AccessReviewService) RevokeAdmin(principal *domain.Principal, reviewID, adminID int32, comment string, client domain.ClientInfo) error {
+	decidedBy := principal.AdminID
+
+	if err := s.checkPending(reviewID, adminID); err != nil {
+		return err
+	}
+
+	if adminID == decidedBy {
+		return domain.ErrCannotDisableSelf
+	}
+
+	admin, err := s.AdminRepository.GetAdminByID(adminID)
+	if err != nil && err != domain.ErrAdminNotFound {
+		return err
+	}
+	if err == nil {
+		if err := checkRoleGrantable(s.RoleRepository, principal, admin.Role); err != nil {
+			return err
+		}
+	}
+
+	reason := fmt.Sprintf("Revoked in access review %d", reviewID)
+	if comment != "" {
+		reason += ": " + comment
+	}
+
+	err = s.AdminRepository.DisableAdmin(adminID, decidedBy, reason)
+	if err != nil && err != domain.ErrAdminNotFound {
+		return err
+	}
+
+	// An admin deleted since the review opened has nothing left to disable, the decision is still recorded
+	if err == nil {
+		s.recordSecurityEvent(&domain.SecurityEvent{
+			AdminID:   adminID,
+			EventType: domain.SecurityEventAdminDisabled,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			Details: map[string]interface{}{
+				"disabled_by":      decidedBy,
+				"reason":           reason,
+				"access_review_id": reviewID,
+			},
+		})
+	}
+
+	return s.AccessReviewRepository.DecideAccessReviewItem(reviewID, adminID, decidedBy, domain.AccessReviewRevoked, comment)
+}
+
+func (s *AccessReviewService) CloseAccessReview(id, closedBy int32, client domain.ClientInfo) error {
+	if err := s.AccessReviewRepository.CloseAccessReview(id, closedBy); err != nil {
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   closedBy,
+		EventType: domain.SecurityEventAccessReviewClosed,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"access_review_id": id,
+		},
+	})
+
+	return nil
+}
+
+// DisableDormantAdmins disables the admins who have not signed in for the configured number of days
+func (s *AccessReviewService) DisableDormantAdmins() error {
+	if s.Config.DisableDormancyCheck {
+		return nil
+	}
+
+	since := time.Now().AddDate(0, 0, -s.Config.DormantAfterDays)
+	reason := fmt.Sprintf("No login for %d days", s.Config.DormantAfterDays)
+
+	ids, err := s.AdminRepository.DisableDormantAdmins(since, reason)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		s.recordSecurityEvent(&domain.SecurityEvent{
+			AdminID:   id,
+			EventType: domain.SecurityEventAdminDisabled,
+			Details: map[string]interface{}{
+				"reason":    reason,
+				"automatic": true,
+			},
+		})
+	}
+
+	if len(ids) > 0 {
+		slog.Info("Disabled dormant admins", slog.Int("count", len(ids)))
+	}
+
+	return nil
+}
+
+// RunDormancyJob disables dormant admins right away and then on every interval until ctx is done
+func (s *AccessReviewService) RunDormancyJob(ctx context.Context) {
+	if s.Config.DisableDormancyCheck || s.Config.DormancyCheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.DormancyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DisableDormantAdmins(); err != nil {
+			slog.Error("Error disabling dormant admins:", utils.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkPending makes sure a decision can still be taken before acting on it
+func (s *AccessReviewService) checkPending(reviewID, adminID int32) error {
+	review, err := s.AccessReviewRepository.GetAccessReview(reviewID)
+	if err != nil {
+		return err
+	}
+
+	if review.ClosedAt != nil {
+		return domain.ErrAccessReviewClosed
+	}
+
+	for _, item := range review.Items {
+		if item.AdminID == adminID {
+			if item.Decision != "" {
+				return domain.ErrAccessReviewItemDecided
+			}
+			return nil
+		}
+	}
+
+	return domain.ErrAccessReviewItemNotFound
+}
+
+func (s *AccessReviewService) recordSecurityEvent(event *domain.SecurityEvent) {
+	if err := s.SecurityEventRepository.CreateSecurityEvent(event); err != nil {
+		slog.Error("Error recording security event:", utils.Err(err))
+	}
+}
@@ -1,38 +1,276 @@
 package service
 
 import (
+	"log/slog"
+	"net/mail"
+	"time"
 	"user-admin/internal/domain"
 	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
 )
 
 type AdminService struct {
-	AdminRepository repository.AdminRepository
+	AdminRepository         repository.AdminRepository
+	LoginAttemptRepository  repository.LoginAttemptRepository
+	SecurityEventRepository repository.SecurityEventRepository
+	LoginEventRepository    repository.LoginEventRepository
+	RoleRepository          repository.RoleRepository
+	PasswordValidator       *PasswordValidator
 }
 
-func NewAdminService(adminRepository repository.AdminRepository) *AdminService {
-	return &AdminService{AdminRepository: adminRepository}
+func NewAdminService(
+	adminRepository repository.AdminRepository,
+	loginAttemptRepository repository.LoginAttemptRepository,
+	securityEventRepository repository.SecurityEventRepository,
+	loginEventRepository repository.LoginEventRepository,
+	roleRepository repository.RoleRepository,
+	passwordValidator *PasswordValidator,
+) *AdminService {
+	return &AdminService{
+		AdminRepository:         adminRepository,
+		LoginAttemptRepository:  loginAttemptRepository,
+		SecurityEventRepository: securityEventRepository,
+		LoginEventRepository:    loginEventRepository,
+		RoleRepository:          roleRepository,
+		PasswordValidator:       passwordValidator,
+	}
 }
 
-func (s *AdminService) GetAllAdmins(page, pageSize int) (*domain.AdminsList, error) {
-	return s.AdminRepository.GetAllAdmins(page, pageSize)
+func (s *AdminService) GetAllAdmins(status string, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error) {
+	return s.AdminRepository.GetAllAdmins(status, sort, page)
 }
 
 func (s *AdminService) GetAdminByID(id int32) (*domain.CommonAdminResponse, error) {
 	return s.AdminRepository.GetAdminByID(id)
 }
 
-func (s *AdminService) CreateAdmin(request *domain.CreateAdminRequest) (*domain.CommonAdminResponse, error) {
+func (s *AdminService) CreateAdmin(principal *domain.Principal, request *domain.CreateAdminRequest) (*domain.CommonAdminResponse, error) {
+	if err := validateEmail(request.Email); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.RoleRepository.GetRole(request.Role); err != nil {
+		return nil, err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, request.Role); err != nil {
+		return nil, err
+	}
+
+	if err := s.PasswordValidator.Validate(0, request.Username, request.Password); err != nil {
+		return nil, err
+	}
+
 	return s.AdminRepository.CreateAdmin(request)
 }
 
-func (s *AdminService) UpdateAdmin(request *domain.UpdateAdminRequest) (*domain.CommonAdminResponse, error) {
-	return s.AdminRepository.UpdateAdmin(request)
+// UpdateAdmin changes an admin the caller outranks, and only to a role the caller could grant
+func (s *AdminService) UpdateAdmin(principal *domain.Principal, request *domain.UpdateAdminRequest) (*domain.CommonAdminResponse, error) {
+	if err := validateEmail(request.Email); err != nil {
+		return nil, err
+	}
+
+	current, err := s.AdminRepository.GetAdminByID(request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, current.Role); err != nil {
+		return nil, err
+	}
+
+	if request.Role != "" {
+		if _, err := s.RoleRepository.GetRole(request.Role); err != nil {
+			return nil, err
+		}
+
+		if err := checkRoleGrantable(s.RoleRepository, principal, request.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Password != "" {
+		username := request.Username
+		if username == "" {
+			username = current.Username
+		}
+
+		if err := s.PasswordValidator.Validate(request.ID, username, request.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	admin, err := s.AdminRepository.UpdateAdmin(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new password logs the admin out everywhere, a new role only
+	// drops access tokens that still carry the old one
+	switch {
+	case request.Password != "":
+		err = s.AdminRepository.RevokeAllSessions(request.ID)
+	case request.Role != "":
+		err = s.AdminRepository.InvalidateAccessTokens(request.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return admin, nil
 }
 
-func (s *AdminService) DeleteAdmin(id int32) error {
+// validateEmail accepts an empty email, admins without one just cannot reset their password
+func validateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return domain.ErrInvalidEmail
+	}
+
+	return nil
+}
+
+// DeleteAdmin removes an admin the caller outranks
+func (s *AdminService) DeleteAdmin(principal *domain.Principal, id int32) error {
+	admin, err := s.AdminRepository.GetAdminByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, admin.Role); err != nil {
+		return err
+	}
+
 	return s.AdminRepository.DeleteAdmin(id)
 }
 
-func (s *AdminService) SearchAdmins(query string, page, pageSize int) (*domain.AdminsList, error) {
-	return s.AdminRepository.SearchAdmins(query, page, pageSize)
+func (s *AdminService) SearchAdmins(query, status string, sort domain.Sort, page domain.PageRequest) (*domain.AdminsList, *domain.PageInfo, error) {
+	return s.AdminRepository.SearchAdmins(query, status, sort, page)
+}
+
+// DisableAdmin takes away the access of an admin the caller outranks without deleting
+// them, and logs them out everywhere
+func (s *AdminService) DisableAdmin(principal *domain.Principal, id int32, reason string, client domain.ClientInfo) error {
+	disabledBy := principal.AdminID
+	if id == disabledBy {
+		return domain.ErrCannotDisableSelf
+	}
+
+	admin, err := s.AdminRepository.GetAdminByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, admin.Role); err != nil {
+		return err
+	}
+
+	if err := s.AdminRepository.DisableAdmin(id, disabledBy, reason); err != nil {
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   id,
+		EventType: domain.SecurityEventAdminDisabled,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"disabled_by": disabledBy,
+			"reason":      reason,
+		},
+	})
+
+	return nil
+}
+
+// EnableAdmin reverses DisableAdmin for an admin the caller outranks. The route requires
+// admins:enable, which only super admins hold unless granted to other roles.
+func (s *AdminService) EnableAdmin(principal *domain.Principal, id int32, client domain.ClientInfo) error {
+	enabledBy := principal.AdminID
+
+	admin, err := s.AdminRepository.GetAdminByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, admin.Role); err != nil {
+		return err
+	}
+
+	if err := s.AdminRepository.EnableAdmin(id); err != nil {
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   id,
+		EventType: domain.SecurityEventAdminEnabled,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"enabled_by": enabledBy,
+		},
+	})
+
+	return nil
+}
+
+// UnlockAdmin lifts a lockout caused by failed password or 2FA attempts of an admin the caller outranks
+func (s *AdminService) UnlockAdmin(principal *domain.Principal, id int32, client domain.ClientInfo) error {
+	admin, err := s.AdminRepository.GetAdminByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, admin.Role); err != nil {
+		return err
+	}
+
+	if err := s.LoginAttemptRepository.ResetLoginAttempts(usernameThrottleKey(admin.Username)); err != nil {
+		return err
+	}
+
+	if err := s.LoginAttemptRepository.ResetLoginAttempts(mfaThrottleKey(admin.ID)); err != nil {
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   admin.ID,
+		EventType: domain.SecurityEventLoginUnlock,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"unlocked_by": principal.AdminID,
+		},
+	})
+
+	return nil
+}
+
+// recordSecurityEvent stores the event; failures are logged so that they never fail the request
+func (s *AdminService) recordSecurityEvent(event *domain.SecurityEvent) {
+	if err := s.SecurityEventRepository.CreateSecurityEvent(event); err != nil {
+		slog.Error("Error recording security event:", utils.Err(err))
+	}
+}
+
+func (s *AdminService) GetSecurityEvents(eventType string, page, pageSize int) (*domain.SecurityEventsList, error) {
+	return s.SecurityEventRepository.GetSecurityEvents(eventType, page, pageSize)
+}
+
+// GetLoginEvents lists the login history of one admin
+func (s *AdminService) GetLoginEvents(adminID int32, page, pageSize int) (*domain.LoginEventsList, error) {
+	if _, err := s.AdminRepository.GetAdminByID(adminID); err != nil {
+		return nil, err
+	}
+
+	return s.LoginEventRepository.GetLoginEvents(adminID, page, pageSize)
+}
+
+// GetLoginFailures lists the failed logins of every admin since the given time
+func (s *AdminService) GetLoginFailures(since time.Time, page, pageSize int) (*domain.LoginEventsList, error) {
+	return s.LoginEventRepository.GetLoginFailures(since, page, pageSize)
 }
@@ -2,44 +2,232 @@ package service
 
 import (
 	"log/slog"
+	"user-admin/internal/config"
 	"user-admin/internal/domain"
 	"user-admin/internal/repository"
 	"user-admin/pkg/lib/utils"
-
-	"golang.org/x/crypto/bcrypt"
+	"user-admin/pkg/mailer"
+	"user-admin/pkg/password"
 )
 
 type AdminAuthService struct {
-	AdminAuthRepository repository.AdminAuthRepository
+	AdminAuthRepository     repository.AdminAuthRepository
+	SecurityEventRepository repository.SecurityEventRepository
+	MFARepository           repository.MFARepository
+	LoginAttemptRepository  repository.LoginAttemptRepository
+	LoginEventRepository    repository.LoginEventRepository
+	RoleRepository          repository.RoleRepository
+	MFAConfig               config.MFA
+	LockoutConfig           config.Lockout
+	PasswordValidator       *PasswordValidator
+	PasswordHasher          *password.Hasher
+	PasswordResetRepository repository.PasswordResetRepository
+	AccessPolicyService     *AccessPolicyService
+	Mailer                  mailer.Mailer
+	PasswordResetConfig     config.PasswordReset
+	// LocalLoginDisabled leaves single sign-on as the only way in
+	LocalLoginDisabled bool
+
+	// dummyHash is verified when no admin has the username, so a miss takes as long as a wrong password
+	dummyHash string
 }
 
-func NewAdminAuthService(adminAuthRepository repository.AdminAuthRepository) *AdminAuthService {
-	return &AdminAuthService{AdminAuthRepository: adminAuthRepository}
+func NewAdminAuthService(
+	adminAuthRepository repository.AdminAuthRepository,
+	securityEventRepository repository.SecurityEventRepository,
+	mfaRepository repository.MFARepository,
+	loginAttemptRepository repository.LoginAttemptRepository,
+	loginEventRepository repository.LoginEventRepository,
+	roleRepository repository.RoleRepository,
+	passwordValidator *PasswordValidator,
+	passwordHasher *password.Hasher,
+	passwordResetRepository repository.PasswordResetRepository,
+	accessPolicyService *AccessPolicyService,
+	mailSender mailer.Mailer,
+	cfg *config.Config,
+) *AdminAuthService {
+	dummyHash, err := passwordHasher.Hash("dummy password of unknown usernames")
+	if err != nil {
+		slog.Error("Error hashing dummy password:", utils.Err(err))
+	}
+
+	return &AdminAuthService{
+		AdminAuthRepository:     adminAuthRepository,
+		SecurityEventRepository: securityEventRepository,
+		MFARepository:           mfaRepository,
+		LoginAttemptRepository:  loginAttemptRepository,
+		LoginEventRepository:    loginEventRepository,
+		RoleRepository:          roleRepository,
+		MFAConfig:               cfg.MFA,
+		LockoutConfig:           cfg.Lockout,
+		PasswordValidator:       passwordValidator,
+		PasswordHasher:          passwordHasher,
+		PasswordResetRepository: passwordResetRepository,
+		AccessPolicyService:     accessPolicyService,
+		Mailer:                  mailSender,
+		PasswordResetConfig:     cfg.PasswordReset,
+		LocalLoginDisabled:      cfg.OIDC.DisableLocalLogin,
+		dummyHash:               dummyHash,
+	}
 }
 
-func (s *AdminAuthService) LoginAdmin(username, password string) (string, string, error) {
+func (s *AdminAuthService) LoginAdmin(username, password string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	if s.LocalLoginDisabled {
+		return nil, domain.ErrLocalLoginDisabled
+	}
+
+	if err := s.checkLoginThrottle(usernameThrottleKey(username), ipThrottleKey(client.IPAddress)); err != nil {
+		slog.Warn("Login attempt throttled:", utils.Err(err))
+		var adminID int32
+		if admin, lookupErr := s.AdminAuthRepository.GetAdminByUsername(username); lookupErr == nil {
+			adminID = admin.ID
+		}
+		s.recordLoginEvent(adminID, username, domain.LoginResultLocked, domain.LoginMethodPassword, client)
+		return nil, err
+	}
+
 	admin, err := s.AdminAuthRepository.GetAdminByUsername(username)
 	if err != nil {
 		slog.Error("Error getting admin by username:", utils.Err(err))
-		return "", "", err
+		if err != domain.ErrAdminNotFound {
+			return nil, err
+		}
+
+		// Unknown usernames are answered like wrong passwords, so they cannot be enumerated
+		s.verifyDummyPassword(password)
+		s.registerFailedPasswordLogin(0, username, client)
+		s.recordLoginEvent(0, username, domain.LoginResultBadPassword, domain.LoginMethodPassword, client)
+		return nil, domain.ErrInvalidCredentials
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password))
+	matches, err := s.PasswordHasher.Verify(admin.Password, password)
 	if err != nil {
 		slog.Error("Error comparing passwords:", utils.Err(err))
-		return "", "", domain.ErrInvalidCredentials
+	}
+	if !matches {
+		s.registerFailedPasswordLogin(admin.ID, username, client)
+		s.recordLoginEvent(admin.ID, username, domain.LoginResultBadPassword, domain.LoginMethodPassword, client)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	// A denied login is counted and answered like a wrong password, anything else would confirm
+	// the password to the client. The login event keeps the actual reason.
+	if err := s.checkLoginAllowed(admin, domain.LoginMethodPassword, client); err != nil {
+		if err != domain.ErrAdminDisabled && err != domain.ErrAccessDenied {
+			return nil, err
+		}
+		s.registerFailedPasswordLogin(admin.ID, username, client)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	s.resetLoginAttempts(usernameThrottleKey(username))
+
+	// The plain password is only known here, so this is where outdated hashes get upgraded
+	if s.PasswordHasher.NeedsRehash(admin.Password) {
+		if err := s.AdminAuthRepository.RehashPassword(admin.ID, admin.Password, password); err != nil {
+			slog.Error("Error upgrading password hash:", utils.Err(err))
+		}
+	}
+
+	mfa, err := s.MFARepository.GetMFA(admin.ID)
+	if err != nil && err != domain.ErrMFANotEnrolled {
+		return nil, err
+	}
+
+	// Admins with 2FA get a token that is only good for /auth/mfa/verify
+	if mfa != nil && mfa.Enabled {
+		mfaToken, err := s.AdminAuthRepository.GenerateRestrictedToken(admin, domain.MFATokenPurpose)
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	required, err := s.MFARepository.IsMFARequiredForRole(admin.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Admins whose role requires 2FA have to enroll before they get a session
+	if required {
+		mfaToken, err := s.AdminAuthRepository.GenerateRestrictedToken(admin, domain.MFAEnrollmentTokenPurpose)
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.LoginResult{MFAEnrollmentRequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.finishLogin(admin, domain.LoginMethodPassword, client)
+}
+
+// CompleteLogin starts a session for an admin who finished a pending login step with the given method
+func (s *AdminAuthService) CompleteLogin(adminID int32, method string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	admin, err := s.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		slog.Error("Error getting admin by ID:", utils.Err(err))
+		return nil, err
+	}
+
+	// Checked again, as the client may have moved or the time window closed since the password step
+	if err := s.checkLoginAllowed(admin, method, client); err != nil {
+		return nil, err
 	}
 
-	accessToken, refreshToken, err := s.AdminAuthRepository.GenerateTokenPair(admin)
+	return s.finishLogin(admin, method, client)
+}
+
+// checkLoginAllowed records a denied login when the admin is disabled or
+// their access policies do not allow the client
+func (s *AdminAuthService) checkLoginAllowed(admin *domain.Admin, method string, client domain.ClientInfo) error {
+	if admin.Disabled {
+		s.recordLoginEvent(admin.ID, admin.Username, domain.LoginResultDisabled, method, client)
+		return domain.ErrAdminDisabled
+	}
+
+	err := s.AccessPolicyService.CheckAccess(admin.ID, client)
+	if err == domain.ErrAccessDenied {
+		s.recordLoginEvent(admin.ID, admin.Username, domain.LoginResultDenied, method, client)
+	}
+
+	return err
+}
+
+// finishLogin holds back the session while the admin still has to replace an expired or reset password
+func (s *AdminAuthService) finishLogin(admin *domain.Admin, method string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	s.recordLoginEvent(admin.ID, admin.Username, domain.LoginResultSuccess, method, client)
+
+	if admin.PasswordChangeRequired || s.PasswordValidator.Policy.IsExpired(admin.PasswordChangedAt) {
+		passwordChangeToken, err := s.AdminAuthRepository.GenerateRestrictedToken(admin, domain.PasswordChangeTokenPurpose)
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.LoginResult{PasswordChangeRequired: true, PasswordChangeToken: passwordChangeToken}, nil
+	}
+
+	return s.issueTokens(admin, client)
+}
+
+func (s *AdminAuthService) issueTokens(admin *domain.Admin, client domain.ClientInfo) (*domain.LoginResult, error) {
+	// Every login gets its own session so other devices stay signed in
+	session, err := s.AdminAuthRepository.CreateSession(admin.ID, client)
+	if err != nil {
+		slog.Error("Error creating session:", utils.Err(err))
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.AdminAuthRepository.GenerateTokenPair(admin, session.ID)
 	if err != nil {
 		slog.Error("Error generating token pair:", utils.Err(err))
-		return "", "", err
+		return nil, err
 	}
 
-	return accessToken, refreshToken, nil
+	return &domain.LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}, nil
 }
 
-func (s *AdminAuthService) RefreshTokens(refreshToken string) (string, string, error) {
+func (s *AdminAuthService) RefreshTokens(refreshToken string, client domain.ClientInfo) (string, string, error) {
 	claims, err := s.AdminAuthRepository.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		slog.Error("Error validating refresh token:", utils.Err(err))
@@ -52,16 +240,44 @@ func (s *AdminAuthService) RefreshTokens(refreshToken string) (string, string, e
 		return "", "", domain.ErrInvalidRefreshToken
 	}
 
+	sessionID, ok := claims["sid"].(string)
+	if !ok {
+		slog.Error("Session ID not found in refresh token claims")
+		return "", "", domain.ErrInvalidRefreshToken
+	}
+
 	// Convert adminID to int
 	adminID := int(adminIDFloat)
 
+	// Checked before the rotation, so a denied client can come back with the same refresh token
+	if err := s.AccessPolicyService.CheckAccess(int32(adminID), client); err != nil {
+		return "", "", err
+	}
+
+	// Every refresh token can be exchanged exactly once. Seeing one again means
+	// it was stolen, so the whole token family (the session) is revoked.
+	err = s.AdminAuthRepository.MarkRefreshTokenUsed(refreshToken)
+	if err == domain.ErrRefreshTokenReused {
+		s.handleRefreshTokenReuse(int32(adminID), sessionID, client)
+		return "", "", err
+	}
+	if err != nil {
+		slog.Error("Error rotating refresh token:", utils.Err(err))
+		return "", "", err
+	}
+
 	admin, err := s.AdminAuthRepository.GetAdminByID(adminID)
 	if err != nil {
 		slog.Error("Error getting admin by ID:", utils.Err(err))
 		return "", "", err
 	}
 
-	newAccessToken, newRefreshToken, err := s.AdminAuthRepository.GenerateTokenPair(admin)
+	// Disabling revokes the sessions as well, this only covers a refresh racing with it
+	if admin.Disabled {
+		return "", "", domain.ErrAdminDisabled
+	}
+
+	newAccessToken, newRefreshToken, err := s.AdminAuthRepository.GenerateTokenPair(admin, sessionID)
 	if err != nil {
 		slog.Error("Error generating token pair:", utils.Err(err))
 		return "", "", err
@@ -70,6 +286,46 @@ func (s *AdminAuthService) RefreshTokens(refreshToken string) (string, string, e
 	return newAccessToken, newRefreshToken, nil
 }
 
+func (s *AdminAuthService) handleRefreshTokenReuse(adminID int32, sessionID string, client domain.ClientInfo) {
+	slog.Warn("Refresh token reuse detected, revoking session", slog.String("session_id", sessionID))
+
+	if err := s.AdminAuthRepository.RevokeSession(adminID, sessionID); err != nil && err != domain.ErrSessionNotFound {
+		slog.Error("Error revoking reused token family:", utils.Err(err))
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   adminID,
+		EventType: domain.SecurityEventRefreshTokenReuse,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"session_id": sessionID,
+		},
+	})
+}
+
+// recordLoginEvent adds the attempt to the login history, like recordSecurityEvent it never fails the login
+func (s *AdminAuthService) recordLoginEvent(adminID int32, username, result, method string, client domain.ClientInfo) {
+	err := s.LoginEventRepository.CreateLoginEvent(&domain.LoginEvent{
+		AdminID:   adminID,
+		Username:  username,
+		Result:    result,
+		Method:    method,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		slog.Error("Error recording login event:", utils.Err(err))
+	}
+}
+
+// recordSecurityEvent stores the event; failures are logged so that they never block the auth flow
+func (s *AdminAuthService) recordSecurityEvent(event *domain.SecurityEvent) {
+	if err := s.SecurityEventRepository.CreateSecurityEvent(event); err != nil {
+		slog.Error("Error recording security event:", utils.Err(err))
+	}
+}
+
 func (s *AdminAuthService) LogoutAdmin(refreshToken string) error {
 	err := s.AdminAuthRepository.DeleteRefreshToken(refreshToken)
 	if err != nil {
@@ -79,3 +335,146 @@ func (s *AdminAuthService) LogoutAdmin(refreshToken string) error {
 
 	return nil
 }
+
+// GetSessions lists the active sessions of an admin, flagging the one the request was made from
+func (s *AdminAuthService) GetSessions(adminID int32, currentSessionID string) (*domain.SessionsList, error) {
+	sessions, err := s.AdminAuthRepository.GetSessionsByAdminID(adminID)
+	if err != nil {
+		slog.Error("Error getting sessions:", utils.Err(err))
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return &domain.SessionsList{Sessions: sessions}, nil
+}
+
+func (s *AdminAuthService) RevokeSession(adminID int32, sessionID string) error {
+	err := s.AdminAuthRepository.RevokeSession(adminID, sessionID)
+	if err != nil {
+		slog.Error("Error revoking session:", utils.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// verifyDummyPassword spends the time of a password check on a login without an admin
+func (s *AdminAuthService) verifyDummyPassword(password string) {
+	if s.dummyHash != "" {
+		_, _ = s.PasswordHasher.Verify(s.dummyHash, password)
+	}
+}
+
+// RevokeAdminSession ends a session of another admin the caller outranks
+func (s *AdminAuthService) RevokeAdminSession(principal *domain.Principal, adminID int32, sessionID string) error {
+	if err := s.checkOutranks(principal, adminID); err != nil {
+		return err
+	}
+
+	return s.RevokeSession(adminID, sessionID)
+}
+
+// checkOutranks refuses to act on an admin whose role holds permissions the caller does not
+func (s *AdminAuthService) checkOutranks(principal *domain.Principal, adminID int32) error {
+	admin, err := s.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		return err
+	}
+
+	return checkRoleGrantable(s.RoleRepository, principal, admin.Role)
+}
+
+// Impersonate lets an admin act as another admin to reproduce what they see.
+// Only admins the caller outranks can be impersonated, and an impersonation cannot be nested.
+func (s *AdminAuthService) Impersonate(principal *domain.Principal, adminID int32, client domain.ClientInfo) (*domain.Impersonation, error) {
+	if principal.IsImpersonated() || principal.AdminID == adminID {
+		return nil, domain.ErrImpersonationNotAllowed
+	}
+
+	admin, err := s.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		slog.Error("Error getting admin by ID:", utils.Err(err))
+		return nil, err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, admin.Role); err != nil {
+		return nil, err
+	}
+
+	if admin.Disabled {
+		return nil, domain.ErrAdminDisabled
+	}
+
+	accessToken, expiresAt, err := s.AdminAuthRepository.GenerateImpersonationToken(admin, principal.AdminID, principal.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   admin.ID,
+		EventType: domain.SecurityEventImpersonationStarted,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"impersonator_id": principal.AdminID,
+			"expires_at":      expiresAt,
+		},
+	})
+
+	return &domain.Impersonation{
+		AccessToken:    accessToken,
+		ExpiresAt:      expiresAt,
+		Impersonated:   true,
+		ImpersonatorID: principal.AdminID,
+		Admin: domain.CommonAdminResponse{
+			ID:       admin.ID,
+			Username: admin.Username,
+			Role:     admin.Role,
+			Email:    admin.Email,
+		},
+	}, nil
+}
+
+// ChangePassword replaces the admin's password after checking the current one. All other
+// sessions are logged out, and the caller gets a fresh session in exchange.
+func (s *AdminAuthService) ChangePassword(adminID int32, currentPassword, newPassword string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	if s.LocalLoginDisabled {
+		return nil, domain.ErrLocalLoginDisabled
+	}
+
+	admin, err := s.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		slog.Error("Error getting admin by ID:", utils.Err(err))
+		return nil, err
+	}
+
+	if err := s.checkLoginThrottle(usernameThrottleKey(admin.Username), ipThrottleKey(client.IPAddress)); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.PasswordHasher.Verify(admin.Password, currentPassword)
+	if err != nil {
+		slog.Error("Error comparing passwords:", utils.Err(err))
+	}
+	if !matches {
+		s.registerFailedPasswordLogin(admin.ID, admin.Username, client)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if currentPassword == newPassword {
+		return nil, domain.ErrPasswordReused
+	}
+
+	if err := s.PasswordValidator.Validate(admin.ID, admin.Username, newPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.AdminAuthRepository.UpdatePassword(admin.ID, newPassword); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(admin, client)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"user-admin/internal/config"
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/mailer"
+)
+
+type InvitationService struct {
+	InvitationRepository    repository.InvitationRepository
+	RoleRepository          repository.RoleRepository
+	SecurityEventRepository repository.SecurityEventRepository
+	PasswordValidator       *PasswordValidator
+	Mailer                  mailer.Mailer
+	InvitationConfig        config.Invitation
+}
+
+func NewInvitationService(
+	invitationRepository repository.InvitationRepository,
+	roleRepository repository.RoleRepository,
+	securityEventRepository repository.SecurityEventRepository,
+	passwordValidator *PasswordValidator,
+	mailSender mailer.Mailer,
+	cfg config.Invitation,
+) *InvitationService {
+	return &InvitationService{
+		InvitationRepository:    invitationRepository,
+		RoleRepository:          roleRepository,
+		SecurityEventRepository: securityEventRepository,
+		PasswordValidator:       passwordValidator,
+		Mailer:                  mailSender,
+		InvitationConfig:        cfg,
+	}
+}
+
+// CreateInvitation reserves the username for a new admin and hands out the token they accept the
+// invitation with. The invitation is mailed when an email is given, the token is returned either way.
+func (s *InvitationService) CreateInvitation(principal *domain.Principal, request *domain.CreateInvitationRequest, client domain.ClientInfo) (*domain.CreatedInvitation, error) {
+	request.Username = strings.TrimSpace(request.Username)
+	request.Email = strings.TrimSpace(request.Email)
+
+	if err := validateEmail(request.Email); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.RoleRepository.GetRole(request.Role); err != nil {
+		return nil, err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, request.Role); err != nil {
+		return nil, err
+	}
+
+	// API keys invite on nobody's behalf
+	invitedBy := principal.AdminID
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.InvitationRepository.CreateInvitation(&domain.Invitation{
+		Username:  request.Username,
+		Email:     request.Email,
+		Role:      request.Role,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(s.InvitationConfig.InvitationTTL),
+	}, token)
+	if err != nil {
+		slog.Error("Error creating invitation:", utils.Err(err))
+		return nil, err
+	}
+
+	s.recordSecurityEvent(invitedBy, domain.SecurityEventAdminInvited, invitation, client)
+
+	return s.deliver(invitation, token), nil
+}
+
+func (s *InvitationService) GetInvitations() (*domain.InvitationsList, error) {
+	return s.InvitationRepository.GetInvitations()
+}
+
+// ResendInvitation hands out a new token with a fresh expiry, the previous token stops working
+func (s *InvitationService) ResendInvitation(id int32) (*domain.CreatedInvitation, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.InvitationRepository.RenewInvitation(id, token, time.Now().Add(s.InvitationConfig.InvitationTTL))
+	if err != nil {
+		slog.Error("Error renewing invitation:", utils.Err(err))
+		return nil, err
+	}
+
+	return s.deliver(invitation, token), nil
+}
+
+func (s *InvitationService) RevokeInvitation(id, revokedBy int32, client domain.ClientInfo) error {
+	invitation, err := s.InvitationRepository.RevokeInvitation(id)
+	if err != nil {
+		slog.Error("Error revoking invitation:", utils.Err(err))
+		return err
+	}
+
+	s.recordSecurityEvent(revokedBy, domain.SecurityEventInvitationRevoked, invitation, client)
+
+	return nil
+}
+
+// AcceptInvitation creates the invited admin with the password they chose
+func (s *InvitationService) AcceptInvitation(token, password string, client domain.ClientInfo) (*domain.CommonAdminResponse, error) {
+	invitation, err := s.InvitationRepository.GetPendingInvitation(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is only spent once the password is acceptable
+	if err := s.PasswordValidator.Validate(0, invitation.Username, password); err != nil {
+		return nil, err
+	}
+
+	admin, err := s.InvitationRepository.AcceptInvitation(token, password)
+	if err != nil {
+		slog.Error("Error accepting invitation:", utils.Err(err))
+		return nil, err
+	}
+
+	s.recordSecurityEvent(admin.ID, domain.SecurityEventInvitationAccepted, invitation, client)
+
+	return admin, nil
+}
+
+// deliver mails the invitation when the invitee has an email. A failed mail is not fatal,
+// the token is returned to the inviting admin who can pass it on.
+func (s *InvitationService) deliver(invitation *domain.Invitation, token string) *domain.CreatedInvitation {
+	created := &domain.CreatedInvitation{
+		Invitation: *invitation,
+		Token:      token,
+	}
+
+	if s.InvitationConfig.AcceptURL != "" {
+		created.Link = tokenLink(s.InvitationConfig.AcceptURL, token)
+	}
+
+	if invitation.Email == "" {
+		return created
+	}
+
+	err := s.Mailer.Send(mailer.Message{
+		To:      invitation.Email,
+		Subject: "You have been invited to the admin panel",
+		Body:    s.invitationMailBody(invitation, token),
+	})
+	if err != nil {
+		slog.Error("Error sending invitation mail:", utils.Err(err))
+		return created
+	}
+
+	created.MailSent = true
+
+	return created
+}
+
+func (s *InvitationService) invitationMailBody(invitation *domain.Invitation, token string) string {
+	return fmt.Sprintf(
+		"Hello %s,\n\nYou have been invited to the admin panel as %s. Use the link below to choose your password and activate your account:\n\n%s\n\nThe link expires on %s and can only be used once.\n",
+		invitation.Username, invitation.Role, tokenLink(s.InvitationConfig.AcceptURL, token), invitation.ExpiresAt.UTC().Format(time.RFC1123),
+	)
+}
+
+func (s *InvitationService) recordSecurityEvent(adminID int32, eventType string, invitation *domain.Invitation, client domain.ClientInfo) {
+	err := s.SecurityEventRepository.CreateSecurityEvent(&domain.SecurityEvent{
+		AdminID:   adminID,
+		EventType: eventType,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"invitation_id": invitation.ID,
+			"username":      invitation.Username,
+			"role":          invitation.Role,
+		},
+	})
+	if err != nil {
+		slog.Error("Error recording security event:", utils.Err(err))
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+)
+
+func usernameThrottleKey(username string) string {
+	return "user:" + username
+}
+
+func ipThrottleKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+func mfaThrottleKey(adminID int32) string {
+	return fmt.Sprintf("mfa:%d", adminID)
+}
+
+// checkLoginThrottle refuses the attempt while any of the keys is locked or still backing off
+func (s *AdminAuthService) checkLoginThrottle(keys ...string) error {
+	now := time.Now()
+
+	for _, key := range keys {
+		attempt, err := s.LoginAttemptRepository.GetLoginAttempt(key)
+		if err != nil {
+			return err
+		}
+
+		if attempt.LockedUntil.After(now) {
+			return &domain.LoginThrottledError{RetryAfter: attempt.LockedUntil.Sub(now), Locked: true}
+		}
+
+		if attempt.Failures == 0 || now.Sub(attempt.LastFailureAt) >= s.LockoutConfig.Window {
+			continue
+		}
+
+		nextAttemptAt := attempt.LastFailureAt.Add(s.loginBackoff(attempt.Failures))
+		if nextAttemptAt.After(now) {
+			return &domain.LoginThrottledError{RetryAfter: nextAttemptAt.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// loginBackoff doubles the delay with every failure, up to the configured maximum
+func (s *AdminAuthService) loginBackoff(failures int) time.Duration {
+	backoff := s.LockoutConfig.BackoffBase
+	for i := 1; i < failures && backoff < s.LockoutConfig.BackoffMax; i++ {
+		backoff *= 2
+	}
+
+	if backoff > s.LockoutConfig.BackoffMax {
+		backoff = s.LockoutConfig.BackoffMax
+	}
+
+	return backoff
+}
+
+func (s *AdminAuthService) registerFailedPasswordLogin(adminID int32, username string, client domain.ClientInfo) {
+	s.registerFailedLogin(adminID, usernameThrottleKey(username), s.LockoutConfig.MaxAttempts, client)
+	s.registerFailedLogin(adminID, ipThrottleKey(client.IPAddress), s.LockoutConfig.IPMaxAttempts, client)
+}
+
+// registerFailedLogin counts a failure against the key and locks it once the threshold is reached
+func (s *AdminAuthService) registerFailedLogin(adminID int32, key string, threshold int, client domain.ClientInfo) {
+	attempt, err := s.LoginAttemptRepository.RegisterFailedLogin(key, s.LockoutConfig.Window)
+	if err != nil {
+		slog.Error("Error registering failed login:", utils.Err(err))
+		return
+	}
+
+	if attempt.Failures < threshold {
+		return
+	}
+
+	lockedUntil := time.Now().Add(s.LockoutConfig.Duration)
+	if err := s.LoginAttemptRepository.LockLogin(key, lockedUntil); err != nil {
+		slog.Error("Error locking login:", utils.Err(err))
+		return
+	}
+
+	slog.Warn("Login locked after too many failed attempts", slog.String("key", key))
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   adminID,
+		EventType: domain.SecurityEventLoginLockout,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"key":          key,
+			"failures":     attempt.Failures,
+			"locked_until": lockedUntil,
+		},
+	})
+}
+
+func (s *AdminAuthService) resetLoginAttempts(key string) {
+	if err := s.LoginAttemptRepository.ResetLoginAttempts(key); err != nil {
+		slog.Error("Error resetting login attempts:", utils.Err(err))
+	}
+}
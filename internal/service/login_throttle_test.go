@@ -0,0 +1,179 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"user-admin/internal/config"
+	"user-admin/internal/domain"
+)
+
+// fakeLoginAttempts keeps login attempts in memory
+type fakeLoginAttempts struct {
+	attempts map[string]*domain.LoginAttempt
+}
+
+func (f *fakeLoginAttempts) GetLoginAttempt(key string) (*domain.LoginAttempt, error) {
+	if attempt, ok := f.attempts[key]; ok {
+		copied := *attempt
+		return &copied, nil
+	}
+	return &domain.LoginAttempt{Key: key}, nil
+}
+
+func (f *fakeLoginAttempts) RegisterFailedLogin(key string, window time.Duration) (*domain.LoginAttempt, error) {
+	attempt, ok := f.attempts[key]
+	if !ok {
+		attempt = &domain.LoginAttempt{Key: key}
+		f.attempts[key] = attempt
+	}
+	if time.Since(attempt.LastFailureAt) >= window {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = time.Now()
+
+	copied := *attempt
+	return &copied, nil
+}
+
+func (f *fakeLoginAttempts) LockLogin(key string, until time.Time) error {
+	f.attempts[key].LockedUntil = until
+	return nil
+}
+
+func (f *fakeLoginAttempts) ResetLoginAttempts(key string) error {
+	delete(f.attempts, key)
+	return nil
+}
+
+// fakeSecurityEvents records the events instead of storing them
+type fakeSecurityEvents struct {
+	events []domain.SecurityEvent
+}
+
+func (f *fakeSecurityEvents) CreateSecurityEvent(event *domain.SecurityEvent) error {
+	f.events = append(f.events, *event)
+	return nil
+}
+
+func (f *fakeSecurityEvents) GetSecurityEvents(eventType string, page, pageSize int) (*domain.SecurityEventsList, error) {
+	return nil, nil
+}
+
+func newThrottleTestService() (*AdminAuthService, *fakeLoginAttempts, *fakeSecurityEvents) {
+	attempts := &fakeLoginAttempts{attempts: make(map[string]*domain.LoginAttempt)}
+	events := &fakeSecurityEvents{}
+
+	return &AdminAuthService{
+		LoginAttemptRepository:  attempts,
+		SecurityEventRepository: events,
+		LockoutConfig: config.Lockout{
+			MaxAttempts:   3,
+			IPMaxAttempts: 5,
+			Duration:      15 * time.Minute,
+			Window:        15 * time.Minute,
+			BackoffBase:   time.Second,
+			BackoffMax:    time.Minute,
+		},
+	}, attempts, events
+}
+
+func TestLoginBackoff(t *testing.T) {
+	s, _, _ := newThrottleTestService()
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{8, time.Minute},
+		{1000, time.Minute},
+	}
+
+	for _, test := range tests {
+		if got := s.loginBackoff(test.failures); got != test.want {
+			t.Errorf("loginBackoff(%d) = %s, want %s", test.failures, got, test.want)
+		}
+	}
+
+	s.LockoutConfig.BackoffBase = 2 * time.Minute
+	if got := s.loginBackoff(1); got != time.Minute {
+		t.Errorf("loginBackoff with a base above the maximum = %s, want %s", got, time.Minute)
+	}
+}
+
+func TestCheckLoginThrottle(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		attempt    domain.LoginAttempt
+		wantLocked bool
+		wantRetry  time.Duration // zero when the attempt is allowed
+	}{
+		{"no failures", domain.LoginAttempt{}, false, 0},
+		{"locked", domain.LoginAttempt{Failures: 3, LastFailureAt: now, LockedUntil: now.Add(10 * time.Minute)}, true, 10 * time.Minute},
+		{"lock expired", domain.LoginAttempt{Failures: 2, LastFailureAt: now.Add(-time.Minute), LockedUntil: now.Add(-time.Second)}, false, 0},
+		{"backing off", domain.LoginAttempt{Failures: 3, LastFailureAt: now.Add(-time.Second)}, false, 3 * time.Second},
+		{"backoff over", domain.LoginAttempt{Failures: 3, LastFailureAt: now.Add(-5 * time.Second)}, false, 0},
+		{"outside the window", domain.LoginAttempt{Failures: 100, LastFailureAt: now.Add(-16 * time.Minute)}, false, 0},
+	}
+
+	for _, test := range tests {
+		s, attempts, _ := newThrottleTestService()
+		attempt := test.attempt
+		attempts.attempts["user:jdoe"] = &attempt
+
+		err := s.checkLoginThrottle("user:jdoe", "ip:10.0.0.1")
+		if test.wantRetry == 0 {
+			if err != nil {
+				t.Errorf("%s: checkLoginThrottle = %v, want nil", test.name, err)
+			}
+			continue
+		}
+
+		throttled, ok := err.(*domain.LoginThrottledError)
+		if !ok {
+			t.Errorf("%s: checkLoginThrottle = %v, want a LoginThrottledError", test.name, err)
+			continue
+		}
+		if throttled.Locked != test.wantLocked {
+			t.Errorf("%s: Locked = %v, want %v", test.name, throttled.Locked, test.wantLocked)
+		}
+		if diff := test.wantRetry - throttled.RetryAfter; diff < 0 || diff > time.Second {
+			t.Errorf("%s: RetryAfter = %s, want about %s", test.name, throttled.RetryAfter, test.wantRetry)
+		}
+	}
+}
+
+func TestRegisterFailedLoginLocksAtThreshold(t *testing.T) {
+	s, attempts, events := newThrottleTestService()
+	client := domain.ClientInfo{IPAddress: "10.0.0.1"}
+
+	for i := 1; i <= 3; i++ {
+		if attempt := attempts.attempts["user:jdoe"]; attempt != nil && !attempt.LockedUntil.IsZero() {
+			t.Fatalf("locked after %d failures, want the lock on the 3rd", i-1)
+		}
+		s.registerFailedPasswordLogin(7, "jdoe", client)
+	}
+
+	locked := attempts.attempts["user:jdoe"].LockedUntil
+	if want := time.Now().Add(15 * time.Minute); locked.Before(want.Add(-time.Second)) || locked.After(want) {
+		t.Errorf("LockedUntil = %s, want about %s", locked, want)
+	}
+
+	if !attempts.attempts["ip:10.0.0.1"].LockedUntil.IsZero() {
+		t.Error("the IP was locked before reaching its own threshold")
+	}
+
+	if len(events.events) != 1 {
+		t.Fatalf("recorded %d security events, want 1", len(events.events))
+	}
+	if event := events.events[0]; event.EventType != domain.SecurityEventLoginLockout || event.AdminID != 7 {
+		t.Errorf("unexpected security event %+v", event)
+	}
+}
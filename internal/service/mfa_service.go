@@ -0,0 +1,207 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"log/slog"
+	"strings"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// mfaCodeSkew is the number of 30 second steps accepted around the current one
+	mfaCodeSkew = 1
+)
+
+// VerifyMFA finishes a two-step login with either a TOTP or a recovery code
+func (s *AdminAuthService) VerifyMFA(mfaToken, code, recoveryCode string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	claims, err := s.AdminAuthRepository.ValidateMFAToken(mfaToken, domain.MFATokenPurpose)
+	if err != nil {
+		return nil, err
+	}
+
+	adminIDFloat, ok := claims["id"].(float64)
+	if !ok {
+		return nil, domain.ErrInvalidMFAToken
+	}
+	adminID := int32(adminIDFloat)
+
+	method := domain.LoginMethodTOTP
+	if recoveryCode != "" {
+		method = domain.LoginMethodRecoveryCode
+	}
+
+	// Codes are short, so guessing them is throttled like passwords
+	if err := s.checkLoginThrottle(mfaThrottleKey(adminID)); err != nil {
+		s.recordLoginEvent(adminID, "", domain.LoginResultLocked, method, client)
+		return nil, err
+	}
+
+	if recoveryCode != "" {
+		err = s.MFARepository.UseRecoveryCode(adminID, recoveryCode)
+	} else {
+		err = s.checkMFACode(adminID, code)
+	}
+	if err != nil {
+		slog.Error("Error verifying MFA code:", utils.Err(err))
+		if err == domain.ErrInvalidMFACode {
+			s.registerFailedLogin(adminID, mfaThrottleKey(adminID), s.LockoutConfig.MaxAttempts, client)
+			s.recordLoginEvent(adminID, "", domain.LoginResultMFAFailed, method, client)
+		}
+		return nil, err
+	}
+
+	s.resetLoginAttempts(mfaThrottleKey(adminID))
+
+	return s.CompleteLogin(adminID, method, client)
+}
+
+// EnrollMFA generates a new TOTP secret that has to be confirmed with a code
+func (s *AdminAuthService) EnrollMFA(adminID int32) (*domain.MFAEnrollment, error) {
+	admin, err := s.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		slog.Error("Error getting admin by ID:", utils.Err(err))
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		slog.Error("Error generating TOTP secret:", utils.Err(err))
+		return nil, err
+	}
+
+	if err := s.MFARepository.SaveMFASecret(adminID, secret); err != nil {
+		return nil, err
+	}
+
+	return &domain.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.MFAConfig.Issuer, admin.Username, secret),
+	}, nil
+}
+
+// ConfirmMFAEnrollment enables 2FA and returns the recovery codes, which are shown only once
+func (s *AdminAuthService) ConfirmMFAEnrollment(adminID int32, code string) ([]string, error) {
+	mfa, err := s.MFARepository.GetMFA(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa.Enabled {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaCodeSkew)
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	recoveryCodes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		slog.Error("Error generating recovery codes:", utils.Err(err))
+		return nil, err
+	}
+
+	if err := s.MFARepository.EnableMFA(adminID, recoveryCodes); err != nil {
+		return nil, err
+	}
+
+	if err := s.MFARepository.UseMFAStep(adminID, step); err != nil {
+		slog.Error("Error storing used MFA step:", utils.Err(err))
+	}
+
+	return recoveryCodes, nil
+}
+
+// DisableMFA turns off 2FA for the admin, unless their role requires it
+func (s *AdminAuthService) DisableMFA(adminID int32, role, code string) error {
+	required, err := s.MFARepository.IsMFARequiredForRole(role)
+	if err != nil {
+		return err
+	}
+
+	if required {
+		return domain.ErrMFARequired
+	}
+
+	if err := s.checkMFACode(adminID, code); err != nil {
+		return err
+	}
+
+	return s.MFARepository.DeleteMFA(adminID)
+}
+
+// ResetMFA removes the enrollment of a locked-out admin so they can enroll again
+func (s *AdminAuthService) ResetMFA(principal *domain.Principal, adminID int32, client domain.ClientInfo) error {
+	if err := s.checkOutranks(principal, adminID); err != nil {
+		return err
+	}
+
+	if err := s.MFARepository.DeleteMFA(adminID); err != nil {
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   adminID,
+		EventType: domain.SecurityEventMFAReset,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"reset_by": principal.AdminID,
+		},
+	})
+
+	return nil
+}
+
+func (s *AdminAuthService) GetRoleMFARequirements() (*domain.RoleMFARequirementsList, error) {
+	requirements, err := s.MFARepository.GetRoleMFARequirements()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RoleMFARequirementsList{Roles: requirements}, nil
+}
+
+func (s *AdminAuthService) SetRoleMFARequirement(requirement *domain.RoleMFARequirement) error {
+	return s.MFARepository.SetRoleMFARequirement(requirement)
+}
+
+func (s *AdminAuthService) checkMFACode(adminID int32, code string) error {
+	mfa, err := s.MFARepository.GetMFA(adminID)
+	if err != nil {
+		return err
+	}
+
+	if !mfa.Enabled {
+		return domain.ErrMFANotEnrolled
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaCodeSkew)
+	if !ok {
+		return domain.ErrInvalidMFACode
+	}
+
+	return s.MFARepository.UseMFAStep(adminID, step)
+}
+
+func generateRecoveryCodes(count int) ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(raw))
+		codes = append(codes, code[:4]+"-"+code[4:])
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"user-admin/internal/config"
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/oidc"
+)
+
+// oidcAuthRequestExpiration is how long an admin has to finish signing in at the identity provider
+const oidcAuthRequestExpiration = 10 * time.Minute
+
+// OIDCService signs admins in through the identity provider. The provider is trusted
+// with the second factor, so admins signing in this way skip the local 2FA step.
+type OIDCService struct {
+	Client           *oidc.Client
+	OIDCRepository   repository.OIDCRepository
+	AdminRepository  repository.AdminRepository
+	AdminAuthService *AdminAuthService
+	Config           config.OIDC
+}
+
+func NewOIDCService(
+	client *oidc.Client,
+	oidcRepository repository.OIDCRepository,
+	adminRepository repository.AdminRepository,
+	adminAuthService *AdminAuthService,
+	cfg config.OIDC,
+) *OIDCService {
+	return &OIDCService{
+		Client:           client,
+		OIDCRepository:   oidcRepository,
+		AdminRepository:  adminRepository,
+		AdminAuthService: adminAuthService,
+		Config:           cfg,
+	}
+}
+
+// StartLogin returns the URL of the identity provider the admin has to be sent to
+func (s *OIDCService) StartLogin(ctx context.Context) (string, error) {
+	var request domain.OIDCAuthRequest
+	var err error
+
+	if request.State, err = oidc.RandomString(); err != nil {
+		return "", err
+	}
+	if request.Nonce, err = oidc.RandomString(); err != nil {
+		return "", err
+	}
+	if request.CodeVerifier, err = oidc.RandomString(); err != nil {
+		return "", err
+	}
+	request.ExpiresAt = time.Now().Add(oidcAuthRequestExpiration)
+
+	authorizationURL, err := s.Client.AuthCodeURL(ctx, request.State, request.Nonce, request.CodeVerifier)
+	if err != nil {
+		slog.Error("Error building authorization URL:", utils.Err(err))
+		return "", domain.ErrOIDCProviderUnavailable
+	}
+
+	if err := s.OIDCRepository.CreateAuthRequest(&request); err != nil {
+		return "", err
+	}
+
+	return authorizationURL, nil
+}
+
+// CompleteLogin exchanges the code the identity provider redirected back with for a session
+func (s *OIDCService) CompleteLogin(ctx context.Context, code, state string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	request, err := s.OIDCRepository.ConsumeAuthRequest(state)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.Client.Exchange(ctx, code, request.CodeVerifier)
+	if err != nil {
+		slog.Error("Error exchanging authorization code:", utils.Err(err))
+		return nil, domain.ErrOIDCProviderUnavailable
+	}
+
+	claims, err := s.Client.VerifyIDToken(ctx, token.IDToken, request.Nonce)
+	if err != nil {
+		slog.Error("Error verifying id token:", utils.Err(err))
+		return nil, domain.ErrOIDCProviderUnavailable
+	}
+
+	issuer, _ := claims["iss"].(string)
+	subject, _ := claims["sub"].(string)
+	username, _ := claims[s.Config.UsernameClaim].(string)
+	if subject == "" || username == "" {
+		return nil, domain.ErrOIDCMissingClaim
+	}
+
+	role := s.mapRole(claims[s.Config.GroupsClaim])
+	if role == "" {
+		s.AdminAuthService.recordSecurityEvent(&domain.SecurityEvent{
+			EventType: domain.SecurityEventSSOLoginDenied,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			Details: map[string]interface{}{
+				"subject":  subject,
+				"username": username,
+			},
+		})
+		return nil, domain.ErrOIDCNoRole
+	}
+
+	adminID, err := s.resolveAdmin(issuer, subject, username, role, claims, client)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := s.AdminAuthService.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		return nil, err
+	}
+
+	// The identity provider owns group membership, so the role follows it on every sign-in
+	if admin.Role != role {
+		_, err := s.AdminRepository.UpdateAdmin(&domain.UpdateAdminRequest{ID: admin.ID, Role: role})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.AdminRepository.InvalidateAccessTokens(admin.ID); err != nil {
+			return nil, err
+		}
+		admin.Role = role
+	}
+
+	if err := s.AdminAuthService.checkLoginAllowed(admin, domain.LoginMethodSSO, client); err != nil {
+		return nil, err
+	}
+
+	s.AdminAuthService.recordLoginEvent(admin.ID, admin.Username, domain.LoginResultSuccess, domain.LoginMethodSSO, client)
+
+	return s.AdminAuthService.issueTokens(admin, client)
+}
+
+// resolveAdmin finds the admin linked to the identity. Unlinked identities are linked to the
+// admin with the same username, or provisioned when just-in-time provisioning is on.
+func (s *OIDCService) resolveAdmin(issuer, subject, username, role string, claims map[string]interface{}, client domain.ClientInfo) (int32, error) {
+	adminID, err := s.OIDCRepository.GetIdentityAdminID(issuer, subject)
+	if err != domain.ErrOIDCIdentityNotFound {
+		return adminID, err
+	}
+
+	admin, err := s.AdminAuthService.AdminAuthRepository.GetAdminByUsername(username)
+	switch {
+	case err == nil:
+		adminID = admin.ID
+	case err != domain.ErrAdminNotFound:
+		return 0, err
+	case !s.Config.JITProvisioning:
+		return 0, domain.ErrOIDCNotProvisioned
+	default:
+		adminID, err = s.provisionAdmin(username, role, claims, client)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.OIDCRepository.LinkIdentity(adminID, issuer, subject); err != nil {
+		return 0, err
+	}
+
+	return adminID, nil
+}
+
+func (s *OIDCService) provisionAdmin(username, role string, claims map[string]interface{}, client domain.ClientInfo) (int32, error) {
+	// Nobody knows this password, the admin can only sign in through the identity provider
+	password, err := oidc.RandomString()
+	if err != nil {
+		return 0, err
+	}
+
+	email, _ := claims["email"].(string)
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		email = ""
+	}
+
+	admin, err := s.AdminRepository.CreateAdmin(&domain.CreateAdminRequest{
+		Username: username,
+		Password: password,
+		Role:     role,
+		Email:    email,
+	})
+	if err == domain.ErrAdminEmailExists {
+		admin, err = s.AdminRepository.CreateAdmin(&domain.CreateAdminRequest{
+			Username: username,
+			Password: password,
+			Role:     role,
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	s.AdminAuthService.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   admin.ID,
+		EventType: domain.SecurityEventSSOProvisioned,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"role": role,
+		},
+	})
+
+	return admin.ID, nil
+}
+
+// mapRole returns the role of the first mapping whose group the admin is a member of
+func (s *OIDCService) mapRole(groupsClaim interface{}) string {
+	groups := make(map[string]bool)
+
+	switch value := groupsClaim.(type) {
+	case string:
+		groups[value] = true
+	case []interface{}:
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups[name] = true
+			}
+		}
+	}
+
+	for _, mapping := range s.Config.RoleMappings {
+		if groups[mapping.Group] {
+			return mapping.Role
+		}
+	}
+
+	return ""
+}
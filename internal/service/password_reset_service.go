@@ -0,0 +1,151 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/pkg/lib/utils"
+	"user-admin/pkg/mailer"
+)
+
+// secureTokenBytes is the entropy of the single-use tokens handed out by mail
+const secureTokenBytes = 32
+
+// ForgotPassword mails a reset link to the admin with the given email. Unknown emails are
+// not reported to the caller, so the endpoint cannot be used to find out who is an admin.
+func (s *AdminAuthService) ForgotPassword(email string, client domain.ClientInfo) error {
+	if s.LocalLoginDisabled {
+		return domain.ErrLocalLoginDisabled
+	}
+
+	if err := s.checkLoginThrottle(ipThrottleKey(client.IPAddress)); err != nil {
+		return err
+	}
+
+	admin, err := s.AdminAuthRepository.GetAdminByEmail(email)
+	if err == domain.ErrAdminNotFound {
+		slog.Info("Password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.PasswordResetConfig.ResetTokenTTL)
+	if err := s.PasswordResetRepository.CreatePasswordResetToken(admin.ID, token, expiresAt); err != nil {
+		return err
+	}
+
+	err = s.Mailer.Send(mailer.Message{
+		To:      admin.Email,
+		Subject: "Reset your admin panel password",
+		Body:    s.passwordResetMailBody(admin, token),
+	})
+	if err != nil {
+		slog.Error("Error sending password reset mail:", utils.Err(err))
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   admin.ID,
+		EventType: domain.SecurityEventPasswordResetRequested,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+
+	return nil
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword and logs the admin out everywhere
+func (s *AdminAuthService) ResetPassword(token, newPassword string, client domain.ClientInfo) error {
+	if s.LocalLoginDisabled {
+		return domain.ErrLocalLoginDisabled
+	}
+
+	if err := s.checkLoginThrottle(ipThrottleKey(client.IPAddress)); err != nil {
+		return err
+	}
+
+	adminID, err := s.PasswordResetRepository.GetPasswordResetAdminID(token)
+	if err == domain.ErrInvalidResetToken {
+		s.registerFailedLogin(0, ipThrottleKey(client.IPAddress), s.LockoutConfig.IPMaxAttempts, client)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	admin, err := s.AdminAuthRepository.GetAdminByID(int(adminID))
+	if err != nil {
+		return err
+	}
+
+	// The token is only spent once the new password is acceptable
+	if err := s.PasswordValidator.Validate(admin.ID, admin.Username, newPassword); err != nil {
+		return err
+	}
+
+	if err := s.PasswordResetRepository.UsePasswordResetToken(token); err != nil {
+		return err
+	}
+
+	if err := s.AdminAuthRepository.UpdatePassword(admin.ID, newPassword); err != nil {
+		return err
+	}
+
+	s.resetLoginAttempts(usernameThrottleKey(admin.Username))
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   admin.ID,
+		EventType: domain.SecurityEventPasswordReset,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+
+	return nil
+}
+
+func (s *AdminAuthService) passwordResetMailBody(admin *domain.Admin, token string) string {
+	link := tokenLink(s.PasswordResetConfig.ResetURL, token)
+
+	return fmt.Sprintf(
+		"Hello %s,\n\nA password reset was requested for your admin account. Use the link below to choose a new password:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not request a reset, you can ignore this mail.\n",
+		admin.Username, link, s.PasswordResetConfig.ResetTokenTTL,
+	)
+}
+
+// tokenLink appends the token to a page of the admin panel, without a page the bare token is used
+func tokenLink(pageURL, token string) string {
+	if pageURL == "" {
+		return token
+	}
+
+	link, err := url.Parse(pageURL)
+	if err != nil {
+		return token
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+func generateSecureToken() (string, error) {
+	buf := make([]byte, secureTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
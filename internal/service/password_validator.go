@@ -0,0 +1,42 @@
+package service
+
+import (
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+	"user-admin/pkg/password"
+)
+
+// PasswordValidator enforces the password policy and history for every way an admin password gets set
+type PasswordValidator struct {
+	Policy                    *password.Policy
+	Hasher                    *password.Hasher
+	PasswordHistoryRepository repository.PasswordHistoryRepository
+}
+
+func NewPasswordValidator(policy *password.Policy, hasher *password.Hasher, passwordHistoryRepository repository.PasswordHistoryRepository) *PasswordValidator {
+	return &PasswordValidator{Policy: policy, Hasher: hasher, PasswordHistoryRepository: passwordHistoryRepository}
+}
+
+// Validate checks the new password of an admin; adminID is zero for admins that do not exist yet
+func (v *PasswordValidator) Validate(adminID int32, username, newPassword string) error {
+	if err := v.Policy.Validate(newPassword, username); err != nil {
+		return err
+	}
+
+	if adminID == 0 || v.Policy.HistorySize() <= 0 {
+		return nil
+	}
+
+	hashes, err := v.PasswordHistoryRepository.GetPasswordHistory(adminID, v.Policy.HistorySize())
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if matches, _ := v.Hasher.Verify(hash, newPassword); matches {
+			return domain.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
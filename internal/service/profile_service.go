@@ -0,0 +1,69 @@
+package service
+
+import (
+	"log/slog"
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
+)
+
+type ProfileService struct {
+	AdminRepository     repository.AdminRepository
+	RoleRepository      repository.RoleRepository
+	AdminAuthRepository repository.AdminAuthRepository
+}
+
+func NewProfileService(adminRepository repository.AdminRepository, roleRepository repository.RoleRepository, adminAuthRepository repository.AdminAuthRepository) *ProfileService {
+	return &ProfileService{
+		AdminRepository:     adminRepository,
+		RoleRepository:      roleRepository,
+		AdminAuthRepository: adminAuthRepository,
+	}
+}
+
+// GetProfile returns the admin behind the principal with their effective permissions
+// and the session the access token was issued for
+func (s *ProfileService) GetProfile(principal *domain.Principal) (*domain.Profile, error) {
+	admin, err := s.AdminRepository.GetAdminByID(principal.AdminID)
+	if err != nil {
+		slog.Error("Error getting admin by ID:", utils.Err(err))
+		return nil, err
+	}
+
+	// The role comes from the database, as the one in the token may be outdated
+	permissions, err := s.RoleRepository.GetRolePermissions(admin.Role)
+	if err != nil {
+		slog.Error("Error getting role permissions:", utils.Err(err))
+		return nil, err
+	}
+
+	if permissions == nil {
+		permissions = make([]string, 0)
+	}
+
+	profile := &domain.Profile{
+		Admin:          *admin,
+		Permissions:    permissions,
+		TokenIssuedAt:  principal.IssuedAt,
+		TokenExpiresAt: principal.ExpiresAt,
+		ImpersonatorID: principal.ImpersonatorID,
+	}
+
+	if principal.SessionID != "" {
+		sessions, err := s.AdminAuthRepository.GetSessionsByAdminID(principal.AdminID)
+		if err != nil {
+			slog.Error("Error getting sessions:", utils.Err(err))
+			return nil, err
+		}
+
+		for i := range sessions {
+			if sessions[i].ID == principal.SessionID {
+				sessions[i].Current = true
+				profile.Session = &sessions[i]
+				break
+			}
+		}
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"regexp"
+	"sync"
+	"time"
+	"user-admin/internal/domain"
+	"user-admin/internal/repository"
+)
+
+// rolePermissionsCacheTTL bounds how long other instances keep serving permissions of a changed role
+const rolePermissionsCacheTTL = 30 * time.Second
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+type RoleService struct {
+	RoleRepository repository.RoleRepository
+
+	mu    sync.RWMutex
+	cache map[string]cachedPermissions
+}
+
+type cachedPermissions struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+func NewRoleService(roleRepository repository.RoleRepository) *RoleService {
+	return &RoleService{
+		RoleRepository: roleRepository,
+		cache:          make(map[string]cachedPermissions),
+	}
+}
+
+func (s *RoleService) GetRoles() (*domain.RolesList, error) {
+	roles, err := s.RoleRepository.GetRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roles.Roles {
+		if roles.Roles[i].Name == domain.SuperAdminRole {
+			roles.Roles[i].Permissions = domain.Permissions
+		}
+	}
+
+	return roles, nil
+}
+
+func (s *RoleService) GetRole(name string) (*domain.Role, error) {
+	role, err := s.RoleRepository.GetRole(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.Name == domain.SuperAdminRole {
+		role.Permissions = domain.Permissions
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) GetPermissions() *domain.PermissionsList {
+	return &domain.PermissionsList{Permissions: domain.Permissions}
+}
+
+func (s *RoleService) CreateRole(principal *domain.Principal, request *domain.CreateRoleRequest) (*domain.Role, error) {
+	if !roleNamePattern.MatchString(request.Name) {
+		return nil, domain.ErrInvalidRoleName
+	}
+
+	if err := validatePermissions(request.Permissions); err != nil {
+		return nil, err
+	}
+
+	if err := checkGrantable(s.RoleRepository, principal, request.Permissions); err != nil {
+		return nil, err
+	}
+
+	return s.RoleRepository.CreateRole(&domain.Role{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: request.Permissions,
+	})
+}
+
+// UpdateRole changes a role the caller outranks, and only to permissions the caller holds
+func (s *RoleService) UpdateRole(principal *domain.Principal, request *domain.UpdateRoleRequest) (*domain.Role, error) {
+	if request.Name == domain.SuperAdminRole {
+		return nil, domain.ErrRoleProtected
+	}
+
+	if err := validatePermissions(request.Permissions); err != nil {
+		return nil, err
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, request.Name); err != nil {
+		return nil, err
+	}
+
+	if err := checkGrantable(s.RoleRepository, principal, request.Permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := s.RoleRepository.UpdateRole(request)
+	if err != nil {
+		return nil, err
+	}
+
+	s.forget(request.Name)
+
+	return role, nil
+}
+
+// DeleteRole removes a custom role the caller outranks, built-in roles are always kept
+func (s *RoleService) DeleteRole(principal *domain.Principal, name string) error {
+	role, err := s.RoleRepository.GetRole(name)
+	if err != nil {
+		return err
+	}
+
+	if role.Builtin {
+		return domain.ErrRoleProtected
+	}
+
+	if err := checkRoleGrantable(s.RoleRepository, principal, name); err != nil {
+		return err
+	}
+
+	if err := s.RoleRepository.DeleteRole(name); err != nil {
+		return err
+	}
+
+	s.forget(name)
+
+	return nil
+}
+
+// RoleExists reports whether admins can be given the role
+func (s *RoleService) RoleExists(name string) (bool, error) {
+	_, err := s.RoleRepository.GetRole(name)
+	if err == domain.ErrRoleNotFound {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// HasPermission answers from a short-lived cache, as it runs on every authorized request
+func (s *RoleService) HasPermission(role, permission string) (bool, error) {
+	// Whatever is stored for it, so permissions added later never lock the super admin out
+	if role == domain.SuperAdminRole {
+		return domain.IsPermission(permission), nil
+	}
+
+	s.mu.RLock()
+	cached, ok := s.cache[role]
+	s.mu.RUnlock()
+
+	if !ok || time.Since(cached.loadedAt) > rolePermissionsCacheTTL {
+		permissions, err := s.RoleRepository.GetRolePermissions(role)
+		if err != nil {
+			return false, err
+		}
+
+		cached = cachedPermissions{permissions: make(map[string]bool, len(permissions)), loadedAt: time.Now()}
+		for _, granted := range permissions {
+			cached.permissions[granted] = true
+		}
+
+		s.mu.Lock()
+		s.cache[role] = cached
+		s.mu.Unlock()
+	}
+
+	return cached.permissions[permission], nil
+}
+
+func (s *RoleService) forget(role string) {
+	s.mu.Lock()
+	delete(s.cache, role)
+	s.mu.Unlock()
+}
+
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !domain.IsPermission(permission) {
+			return domain.ErrUnknownPermission
+		}
+	}
+	return nil
+}
+
+// rolePermissions returns the permissions granted by the role, super_admin grants every one
+func rolePermissions(roles repository.RoleRepository, role string) ([]string, error) {
+	if role == domain.SuperAdminRole {
+		return domain.Permissions, nil
+	}
+
+	return roles.GetRolePermissions(role)
+}
+
+// checkGrantable makes sure nobody hands out more access than they have. Admins hold
+// the permissions of their role, API keys their scopes.
+func checkGrantable(roles repository.RoleRepository, principal *domain.Principal, permissions []string) error {
+	if !principal.IsAdmin() {
+		for _, permission := range permissions {
+			if !principal.APIKey.HasScope(permission) {
+				return domain.ErrRoleOutranksCaller
+			}
+		}
+		return nil
+	}
+
+	granted, err := rolePermissions(roles, principal.Role)
+	if err != nil {
+		return err
+	}
+
+	held := make(map[string]bool, len(granted))
+	for _, permission := range granted {
+		held[permission] = true
+	}
+
+	for _, permission := range permissions {
+		if !held[permission] {
+			return domain.ErrRoleOutranksCaller
+		}
+	}
+	return nil
+}
+
+// checkRoleGrantable checks every permission of the role, it guards both assigning the
+// role and acting on admins that have it
+func checkRoleGrantable(roles repository.RoleRepository, principal *domain.Principal, role string) error {
+	permissions, err := rolePermissions(roles, role)
+	if err != nil {
+		return err
+	}
+
+	return checkGrantable(roles, principal, permissions)
+}
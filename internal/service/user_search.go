@@ -0,0 +1,138 @@
+package service
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"user-admin/internal/domain"
+)
+
+// Fields an unqualified term is highlighted in, phone numbers only when the term has digits
+var freeTextHighlightFields = []string{"first_name", "last_name", "email", "location"}
+
+// highlightUser marks where the terms of the search occur in the fields of the user.
+// Typo tolerant matches that do not occur literally are not marked.
+func highlightUser(user domain.CommonUserResponse, search domain.UserSearchQuery) map[string]string {
+	values := map[string]string{
+		"first_name":   user.FirstName,
+		"last_name":    user.LastName,
+		"email":        user.Email,
+		"location":     user.Location,
+		"phone_number": user.PhoneNumber,
+	}
+
+	matches := make(map[string][][2]int)
+	for _, term := range search.Terms {
+		switch term.Field {
+		case "phone":
+			addMatch(matches, "phone_number", digitsMatch(user.PhoneNumber, domain.Digits(term.Value)))
+		case "name":
+			addMatch(matches, "first_name", textMatches(user.FirstName, []string{term.Value})...)
+			addMatch(matches, "last_name", textMatches(user.LastName, []string{term.Value})...)
+		case "":
+			words := strings.FieldsFunc(term.Value, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			})
+			for _, field := range freeTextHighlightFields {
+				addMatch(matches, field, textMatches(values[field], words)...)
+			}
+			if digits := domain.Digits(term.Value); digits != "" {
+				addMatch(matches, "phone_number", digitsMatch(user.PhoneNumber, digits))
+			}
+		default:
+			addMatch(matches, term.Field, textMatches(values[term.Field], []string{term.Value})...)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil
+	}
+
+	highlights := make(map[string]string, len(matches))
+	for field, spans := range matches {
+		highlights[field] = markSpans(values[field], spans)
+	}
+
+	return highlights
+}
+
+func addMatch(matches map[string][][2]int, field string, spans ...[2]int) {
+	for _, span := range spans {
+		if span[1] > span[0] {
+			matches[field] = append(matches[field], span)
+		}
+	}
+}
+
+// textMatches finds every case-insensitive occurrence of the words, as rune offsets into value
+func textMatches(value string, words []string) [][2]int {
+	text := []rune(strings.ToLower(value))
+
+	var spans [][2]int
+	for _, word := range words {
+		needle := []rune(strings.ToLower(word))
+		if len(needle) == 0 {
+			continue
+		}
+
+		for i := 0; i+len(needle) <= len(text); i++ {
+			if string(text[i:i+len(needle)]) == string(needle) {
+				spans = append(spans, [2]int{i, i + len(needle)})
+			}
+		}
+	}
+
+	return spans
+}
+
+// digitsMatch finds the digits in a formatted phone number, as rune offsets that span the formatting in between
+func digitsMatch(phone, digits string) [2]int {
+	var positions []int
+	var phoneDigits strings.Builder
+
+	for i, r := range []rune(phone) {
+		if r >= '0' && r <= '9' {
+			positions = append(positions, i)
+			phoneDigits.WriteRune(r)
+		}
+	}
+
+	start := strings.Index(phoneDigits.String(), digits)
+	if digits == "" || start < 0 {
+		return [2]int{}
+	}
+
+	return [2]int{positions[start], positions[start+len(digits)-1] + 1}
+}
+
+// markSpans wraps the spans of value in <mark> tags and escapes everything else for HTML
+func markSpans(value string, spans [][2]int) string {
+	text := []rune(strings.ToLower(value))
+	original := []rune(value)
+	if len(text) != len(original) {
+		// Lowercasing changed the length, so the offsets do not fit the original
+		return html.EscapeString(value)
+	}
+
+	marked := make([]bool, len(original))
+	for _, span := range spans {
+		for i := span[0]; i < span[1]; i++ {
+			marked[i] = true
+		}
+	}
+
+	var builder strings.Builder
+	for i, r := range original {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			builder.WriteString("<mark>")
+		}
+
+		builder.WriteString(html.EscapeString(string(r)))
+
+		if marked[i] && (i == len(original)-1 || !marked[i+1]) {
+			builder.WriteString("</mark>")
+		}
+	}
+
+	return builder.String()
+}
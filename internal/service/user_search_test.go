@@ -0,0 +1,103 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"user-admin/internal/domain"
+)
+
+func TestDigitsMatch(t *testing.T) {
+	tests := []struct {
+		phone  string
+		digits string
+		want   [2]int
+	}{
+		{"+99365123456", "65123", [2]int{4, 9}},
+		{"+993 65 12-34-56", "651234", [2]int{5, 13}},
+		{"+993 65 12-34-56", "99365123456", [2]int{1, 16}},
+		{"+993 65 12-34-56", "6", [2]int{5, 6}},
+		{"+993 65 12-34-56", "77", [2]int{}},
+		{"+993 65 12-34-56", "", [2]int{}},
+		{"", "1", [2]int{}},
+	}
+
+	for _, test := range tests {
+		if got := digitsMatch(test.phone, test.digits); got != test.want {
+			t.Errorf("digitsMatch(%q, %q) = %v, want %v", test.phone, test.digits, got, test.want)
+		}
+	}
+}
+
+func TestMarkSpans(t *testing.T) {
+	tests := []struct {
+		value string
+		spans [][2]int
+		want  string
+	}{
+		{"Myrat", [][2]int{{0, 2}}, "<mark>My</mark>rat"},
+		{"Myrat", [][2]int{{0, 2}, {1, 3}}, "<mark>Myr</mark>at"},
+		{"Myrat", [][2]int{{0, 1}, {4, 5}}, "<mark>M</mark>yra<mark>t</mark>"},
+		{"Myrat", nil, "Myrat"},
+		// Everything is escaped, marked or not, so user data cannot inject markup
+		{"<b>Ak</b>", [][2]int{{3, 5}}, "&lt;b&gt;<mark>Ak</mark>&lt;/b&gt;"},
+		{`a"&'b`, [][2]int{{1, 3}}, `a<mark>&#34;&amp;</mark>&#39;b`},
+		{"<script>", [][2]int{{0, 8}}, "<mark>&lt;script&gt;</mark>"},
+		{"Öwez", [][2]int{{0, 1}}, "<mark>Ö</mark>wez"},
+	}
+
+	for _, test := range tests {
+		if got := markSpans(test.value, test.spans); got != test.want {
+			t.Errorf("markSpans(%q, %v) = %q, want %q", test.value, test.spans, got, test.want)
+		}
+	}
+}
+
+func TestHighlightUser(t *testing.T) {
+	user := domain.CommonUserResponse{
+		FirstName:   "Ak",
+		LastName:    "Myradowa",
+		Email:       "ak.myradowa@example.com",
+		Location:    "Mary <north>",
+		PhoneNumber: "+993 65 12-34-56",
+	}
+
+	tests := []struct {
+		query string
+		want  map[string]string
+	}{
+		{"myrad", map[string]string{
+			"last_name": "<mark>Myrad</mark>owa",
+			"email":     "ak.<mark>myrad</mark>owa@example.com",
+		}},
+		{"name:ak", map[string]string{
+			"first_name": "<mark>Ak</mark>",
+		}},
+		{"email:example.com", map[string]string{
+			"email": "ak.myradowa@<mark>example.com</mark>",
+		}},
+		{"location:north", map[string]string{
+			"location": "Mary &lt;<mark>north</mark>&gt;",
+		}},
+		{"65 12-34", map[string]string{
+			"phone_number": "+993 <mark>65 12-34</mark>-56",
+		}},
+		{"myradowa 56", map[string]string{
+			"last_name":    "<mark>Myradowa</mark>",
+			"email":        "ak.<mark>myradowa</mark>@example.com",
+			"phone_number": "+993 65 12-34-<mark>56</mark>",
+		}},
+		// Typo tolerant matches are found by the database but not marked
+		{"mirat", nil},
+	}
+
+	for _, test := range tests {
+		search, err := domain.ParseUserSearchQuery(test.query)
+		if err != nil {
+			t.Fatalf("ParseUserSearchQuery(%q): %v", test.query, err)
+		}
+
+		if got := highlightUser(user, search); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("highlightUser(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
@@ -1,20 +1,35 @@
 package service
 
 import (
+	"context"
+	"log/slog"
+	"time"
+	"user-admin/internal/config"
 	"user-admin/internal/domain"
 	"user-admin/internal/repository"
+	"user-admin/pkg/lib/utils"
 )
 
 type UserService struct {
-	UserRepository repository.UserRepository
+	UserRepository          repository.UserRepository
+	SecurityEventRepository repository.SecurityEventRepository
+	Config                  config.UserRetention
 }
 
-func NewUserService(userRepository repository.UserRepository) *UserService {
-	return &UserService{UserRepository: userRepository}
+func NewUserService(
+	userRepository repository.UserRepository,
+	securityEventRepository repository.SecurityEventRepository,
+	cfg config.UserRetention,
+) *UserService {
+	return &UserService{
+		UserRepository:          userRepository,
+		SecurityEventRepository: securityEventRepository,
+		Config:                  cfg,
+	}
 }
 
-func (s *UserService) GetAllUsers(page, pageSize int) (*domain.UsersList, error) {
-	return s.UserRepository.GetAllUsers(page, pageSize)
+func (s *UserService) GetAllUsers(filter domain.UserFilter, sort domain.Sort, page domain.PageRequest) (*domain.UsersList, *domain.PageInfo, error) {
+	return s.UserRepository.GetAllUsers(filter, sort, page)
 }
 
 func (s *UserService) GetUserByID(id int32) (*domain.GetUserResponse, error) {
@@ -29,8 +44,77 @@ func (s *UserService) UpdateUser(request *domain.UpdateUserRequest) (*domain.Upd
 	return s.UserRepository.UpdateUser(request)
 }
 
-func (s *UserService) DeleteUser(id int32) error {
-	return s.UserRepository.DeleteUser(id)
+// DeleteUser moves the user to the trash. deletedBy is zero when an API key deleted the user.
+func (s *UserService) DeleteUser(id, deletedBy int32) error {
+	return s.UserRepository.DeleteUser(id, deletedBy)
+}
+
+func (s *UserService) RestoreUser(id int32) error {
+	return s.UserRepository.RestoreUser(id)
+}
+
+func (s *UserService) GetDeletedUsers(page domain.PageRequest) (*domain.DeletedUsersList, *domain.PageInfo, error) {
+	return s.UserRepository.GetDeletedUsers(page)
+}
+
+// PurgeUser permanently deletes a user from the trash. The route requires users:purge,
+// which only super admins hold unless granted to other roles.
+func (s *UserService) PurgeUser(id, purgedBy int32, client domain.ClientInfo) error {
+	if err := s.UserRepository.PurgeUser(id); err != nil {
+		return err
+	}
+
+	s.recordSecurityEvent(&domain.SecurityEvent{
+		AdminID:   purgedBy,
+		EventType: domain.SecurityEventUserPurged,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details: map[string]interface{}{
+			"user_id": id,
+		},
+	})
+
+	return nil
+}
+
+// PurgeExpiredUsers permanently deletes the users that have been in the trash longer than the retention
+func (s *UserService) PurgeExpiredUsers() error {
+	if s.Config.DisableUserPurge {
+		return nil
+	}
+
+	purged, err := s.UserRepository.PurgeDeletedUsers(time.Now().Add(-s.Config.DeletedUserRetention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		slog.Info("Purged deleted users", slog.Int64("count", purged))
+	}
+
+	return nil
+}
+
+// RunPurgeJob purges expired users right away and then on every interval until ctx is done
+func (s *UserService) RunPurgeJob(ctx context.Context) {
+	if s.Config.DisableUserPurge {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.DeletedUserPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeExpiredUsers(); err != nil {
+			slog.Error("Error purging deleted users:", utils.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func (s *UserService) BlockUser(id int32) error {
@@ -41,6 +125,22 @@ func (s *UserService) UnblockUser(id int32) error {
 	return s.UserRepository.UnblockUser(id)
 }
 
-func (s *UserService) SearchUsers(query string, page, pageSize int) (*domain.UsersList, error) {
-	return s.UserRepository.SearchUsers(query, page, pageSize)
+// SearchUsers finds the users matching every term of the search and highlights where they matched
+func (s *UserService) SearchUsers(search domain.UserSearchQuery, filter domain.UserFilter, sort domain.Sort, page domain.PageRequest) (*domain.UserSearchResults, *domain.PageInfo, error) {
+	results, info, err := s.UserRepository.SearchUsers(search, filter, sort, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range results.Users {
+		results.Users[i].Highlights = highlightUser(results.Users[i].CommonUserResponse, search)
+	}
+
+	return results, info, nil
+}
+
+func (s *UserService) recordSecurityEvent(event *domain.SecurityEvent) {
+	if err := s.SecurityEventRepository.CreateSecurityEvent(event); err != nil {
+		slog.Error("Error recording security event:", utils.Err(err))
+	}
 }
@@ -0,0 +1,6 @@
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS refresh_token TEXT,
+    ADD COLUMN IF NOT EXISTS refresh_token_created_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS refresh_token_expiration_time TIMESTAMPTZ;
+
+DROP TABLE IF EXISTS admin_sessions;
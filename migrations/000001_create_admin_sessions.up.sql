@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS admin_sessions (
+    id UUID PRIMARY KEY,
+    admin_id INTEGER NOT NULL REFERENCES admins (id) ON DELETE CASCADE,
+    refresh_token_hash TEXT,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS admin_sessions_refresh_token_hash_idx
+    ON admin_sessions (refresh_token_hash)
+    WHERE refresh_token_hash IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS admin_sessions_admin_id_idx
+    ON admin_sessions (admin_id)
+    WHERE revoked_at IS NULL;
+
+ALTER TABLE admins
+    DROP COLUMN IF EXISTS refresh_token,
+    DROP COLUMN IF EXISTS refresh_token_created_at,
+    DROP COLUMN IF EXISTS refresh_token_expiration_time;
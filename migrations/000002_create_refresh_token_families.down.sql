@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS security_events;
+
+ALTER TABLE admin_sessions ADD COLUMN IF NOT EXISTS refresh_token_hash TEXT;
+
+UPDATE admin_sessions s
+SET refresh_token_hash = t.token_hash
+FROM admin_refresh_tokens t
+WHERE t.session_id = s.id AND t.used_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS admin_sessions_refresh_token_hash_idx
+    ON admin_sessions (refresh_token_hash)
+    WHERE refresh_token_hash IS NOT NULL;
+
+DROP TABLE IF EXISTS admin_refresh_tokens;
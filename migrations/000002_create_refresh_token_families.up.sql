@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS admin_refresh_tokens (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES admin_sessions (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS admin_refresh_tokens_session_id_idx
+    ON admin_refresh_tokens (session_id);
+
+INSERT INTO admin_refresh_tokens (id, session_id, token_hash, created_at, expires_at)
+SELECT gen_random_uuid(), id, refresh_token_hash, last_used_at, expires_at
+FROM admin_sessions
+WHERE refresh_token_hash IS NOT NULL;
+
+DROP INDEX IF EXISTS admin_sessions_refresh_token_hash_idx;
+ALTER TABLE admin_sessions DROP COLUMN IF EXISTS refresh_token_hash;
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id SERIAL PRIMARY KEY,
+    admin_id INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    event_type TEXT NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS security_events_admin_id_idx ON security_events (admin_id);
+CREATE INDEX IF NOT EXISTS security_events_created_at_idx ON security_events (created_at);
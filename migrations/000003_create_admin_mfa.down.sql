@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS role_mfa_requirements;
+DROP TABLE IF EXISTS admin_recovery_codes;
+DROP TABLE IF EXISTS admin_mfa;
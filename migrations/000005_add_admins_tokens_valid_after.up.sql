@@ -0,0 +1 @@
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMPTZ;
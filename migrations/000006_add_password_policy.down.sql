@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS admin_password_history;
+
+ALTER TABLE admins
+    DROP COLUMN IF EXISTS password_change_required,
+    DROP COLUMN IF EXISTS password_changed_at;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS admin_password_reset_tokens;
+
+DROP INDEX IF EXISTS admins_email_key;
+
+ALTER TABLE admins DROP COLUMN IF EXISTS email;
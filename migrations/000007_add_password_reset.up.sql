@@ -0,0 +1,15 @@
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS admins_email_key ON admins (lower(email));
+
+CREATE TABLE IF NOT EXISTS admin_password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    admin_id INTEGER NOT NULL REFERENCES admins (id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS admin_password_reset_tokens_admin_id_idx
+    ON admin_password_reset_tokens (admin_id);
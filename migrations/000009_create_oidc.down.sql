@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS admin_identities;
+DROP TABLE IF EXISTS oidc_auth_requests;
@@ -0,0 +1,4 @@
+ALTER TABLE admins DROP CONSTRAINT IF EXISTS admins_role_fkey;
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description, builtin) VALUES
+    ('super_admin', 'Full access, including admins, roles and API keys', TRUE),
+    ('admin', 'Manages users', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:write'),
+    ('admin', 'users:block'),
+    ('admin', 'users:delete'),
+    ('super_admin', 'users:read'),
+    ('super_admin', 'users:write'),
+    ('super_admin', 'users:block'),
+    ('super_admin', 'users:delete'),
+    ('super_admin', 'admins:manage'),
+    ('super_admin', 'roles:manage'),
+    ('super_admin', 'api_keys:manage'),
+    ('super_admin', 'security_events:read')
+ON CONFLICT DO NOTHING;
+
+-- Any other role already assigned to an admin is kept, without permissions
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM admins
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE admins
+    ADD CONSTRAINT admins_role_fkey FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE;
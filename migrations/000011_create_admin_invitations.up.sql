@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS admin_invitations (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    role VARCHAR(50) NOT NULL REFERENCES roles (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    admin_id INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+-- A username can only have one open invitation at a time
+CREATE UNIQUE INDEX IF NOT EXISTS admin_invitations_open_username_key
+    ON admin_invitations (username)
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
@@ -0,0 +1,2 @@
+-- The column stays TEXT, narrowing it would cut off the argon2id hashes stored since
+SELECT 1;
@@ -0,0 +1,2 @@
+-- argon2id hashes in PHC format are longer than bcrypt hashes
+ALTER TABLE admins ALTER COLUMN password TYPE TEXT;
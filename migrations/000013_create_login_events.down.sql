@@ -0,0 +1,5 @@
+ALTER TABLE admins
+    DROP COLUMN IF EXISTS last_login_at,
+    DROP COLUMN IF EXISTS last_seen_at;
+
+DROP TABLE IF EXISTS login_events;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS login_events (
+    id SERIAL PRIMARY KEY,
+    admin_id INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    username VARCHAR(255) NOT NULL DEFAULT '',
+    result VARCHAR(20) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS login_events_admin_id_idx
+    ON login_events (admin_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS login_events_failures_idx
+    ON login_events (created_at DESC)
+    WHERE result <> 'success';
+
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS access_policies (
+    id SERIAL PRIMARY KEY,
+    role VARCHAR(50) REFERENCES roles (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    admin_id INTEGER REFERENCES admins (id) ON DELETE CASCADE,
+    description TEXT NOT NULL DEFAULT '',
+    cidrs CIDR[] NOT NULL DEFAULT '{}',
+    weekdays SMALLINT[] NOT NULL DEFAULT '{}',
+    start_hour SMALLINT CHECK (start_hour BETWEEN 0 AND 23),
+    end_hour SMALLINT CHECK (end_hour BETWEEN 1 AND 24),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- A policy applies either to every admin of a role or to a single admin
+    CHECK ((role IS NULL) <> (admin_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS access_policies_role_idx ON access_policies (role);
+CREATE INDEX IF NOT EXISTS access_policies_admin_id_idx ON access_policies (admin_id);
@@ -0,0 +1,5 @@
+ALTER TABLE admins
+    DROP COLUMN IF EXISTS disabled_by,
+    DROP COLUMN IF EXISTS disabled_reason,
+    DROP COLUMN IF EXISTS disabled_at,
+    DROP COLUMN IF EXISTS disabled;
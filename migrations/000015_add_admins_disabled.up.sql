@@ -0,0 +1,5 @@
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS disabled_reason TEXT,
+    ADD COLUMN IF NOT EXISTS disabled_by INTEGER REFERENCES admins (id) ON DELETE SET NULL;
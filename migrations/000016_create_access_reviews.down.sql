@@ -0,0 +1,5 @@
+ALTER TABLE admins
+    DROP COLUMN IF EXISTS created_at;
+
+DROP TABLE IF EXISTS access_review_items;
+DROP TABLE IF EXISTS access_reviews;
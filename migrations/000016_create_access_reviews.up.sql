@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS access_reviews (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_by INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_by INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    closed_at TIMESTAMPTZ
+);
+
+-- Items keep a snapshot of the admin, so the evidence outlives changes to the account
+CREATE TABLE IF NOT EXISTS access_review_items (
+    review_id INTEGER NOT NULL REFERENCES access_reviews (id) ON DELETE CASCADE,
+    admin_id INTEGER NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    email VARCHAR(255),
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_login_at TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ,
+    decision VARCHAR(20),
+    decided_by INTEGER REFERENCES admins (id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    comment TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (review_id, admin_id)
+);
+
+-- Admins who never signed in count as dormant from the day they were created
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,6 @@
+-- Deleted users stay in the trash until they are restored or purged
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by INTEGER REFERENCES admins (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
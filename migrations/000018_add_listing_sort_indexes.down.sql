@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS admins_created_at_sort_idx;
+DROP INDEX IF EXISTS admins_role_sort_idx;
+DROP INDEX IF EXISTS admins_username_sort_idx;
+
+DROP INDEX IF EXISTS users_registration_date_sort_idx;
+DROP INDEX IF EXISTS users_location_sort_idx;
+DROP INDEX IF EXISTS users_phone_number_sort_idx;
+DROP INDEX IF EXISTS users_last_name_sort_idx;
+DROP INDEX IF EXISTS users_first_name_sort_idx;
@@ -0,0 +1,11 @@
+-- Every sortable field ends with the ID, the tiebreaker that keeps pages from overlapping.
+-- Nullable user fields sort as empty strings, the same way they are returned.
+CREATE INDEX IF NOT EXISTS users_first_name_sort_idx ON users (COALESCE(first_name, ''), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS users_last_name_sort_idx ON users (COALESCE(last_name, ''), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS users_phone_number_sort_idx ON users (phone_number, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS users_location_sort_idx ON users (COALESCE(location, ''), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS users_registration_date_sort_idx ON users (registration_date, id) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS admins_username_sort_idx ON admins (username, id);
+CREATE INDEX IF NOT EXISTS admins_role_sort_idx ON admins (role, id);
+CREATE INDEX IF NOT EXISTS admins_created_at_sort_idx ON admins (created_at, id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS users_phone_digits_trgm_idx;
+DROP INDEX IF EXISTS users_location_trgm_idx;
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_last_name_trgm_idx;
+DROP INDEX IF EXISTS users_first_name_trgm_idx;
+DROP INDEX IF EXISTS users_search_vector_idx;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone_digits,
+    DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed, other schemas may depend on it
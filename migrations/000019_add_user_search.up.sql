@@ -0,0 +1,19 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The simple configuration keeps names as they are written, without language specific stemming
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(first_name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(last_name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(email, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(location, '')), 'C')
+    ) STORED,
+    -- Phone numbers are searched by their digits, so "+993 65 12-34-56" matches "9936512"
+    ADD COLUMN IF NOT EXISTS phone_digits TEXT GENERATED ALWAYS AS (regexp_replace(phone_number, '\D', '', 'g')) STORED;
+
+CREATE INDEX IF NOT EXISTS users_search_vector_idx ON users USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS users_first_name_trgm_idx ON users USING GIN (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_last_name_trgm_idx ON users USING GIN (last_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_location_trgm_idx ON users USING GIN (location gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_phone_digits_trgm_idx ON users USING GIN (phone_digits gin_trgm_ops);
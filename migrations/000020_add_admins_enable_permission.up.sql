@@ -0,0 +1 @@
+-- Other roles are granted admins:enable through the roles API, super admins hold it implicitly
@@ -0,0 +1 @@
+-- No role needs admins:review up front, super admins pass every permission check
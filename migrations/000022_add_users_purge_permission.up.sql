@@ -0,0 +1 @@
+-- users:purge is only ever granted explicitly, there is no existing role to backfill
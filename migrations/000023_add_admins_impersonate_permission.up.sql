@@ -0,0 +1 @@
+-- Impersonation was limited to super admins, who keep it without a grant
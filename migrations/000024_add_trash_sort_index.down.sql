@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users_deleted_at_sort_idx;
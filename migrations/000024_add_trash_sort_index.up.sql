@@ -0,0 +1,2 @@
+-- Matches the order of the trash, so its pages are read straight from the index
+CREATE INDEX IF NOT EXISTS users_deleted_at_sort_idx ON users (deleted_at DESC, id) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,232 @@
+// Package jwtkeys signs and verifies access tokens with a set of rotating keys.
+// Every token names its key in the kid header, so new keys can be introduced
+// while tokens signed with older ones stay valid until they expire.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"user-admin/internal/config"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Key is a single verification key, optionally able to sign
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	// legacy is the shared HS256 secret. It verifies tokens without a kid header,
+	// so switching to asymmetric keys does not log everyone out.
+	legacy *Key
+}
+
+// NewKeySet loads the keys from the JWT config. Without any keys configured
+// tokens keep being signed with the HS256 access secret.
+func NewKeySet(cfg config.JWT) (*KeySet, error) {
+	keySet := &KeySet{keys: make(map[string]*Key)}
+
+	if cfg.AccessSecretKey != "" {
+		secret := []byte(cfg.AccessSecretKey)
+		keySet.legacy = &Key{Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+	}
+
+	for _, keyConfig := range cfg.Keys {
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("loading jwt key %q: %v", keyConfig.ID, err)
+		}
+
+		if _, exists := keySet.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", key.ID)
+		}
+		keySet.keys[key.ID] = key
+	}
+
+	switch {
+	case cfg.SigningKeyID != "":
+		key, ok := keySet.keys[cfg.SigningKeyID]
+		if !ok || key.signKey == nil {
+			return nil, fmt.Errorf("signing key %q is not configured with a private key", cfg.SigningKeyID)
+		}
+		keySet.signing = key
+	case len(keySet.keys) > 0:
+		return nil, fmt.Errorf("signing_key_id is required when jwt keys are configured")
+	case keySet.legacy != nil:
+		keySet.signing = keySet.legacy
+	default:
+		return nil, fmt.Errorf("no jwt signing key configured")
+	}
+
+	return keySet, nil
+}
+
+// Sign returns the signed token, naming the signing key in the kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	if ks.signing.ID != "" {
+		token.Header["kid"] = ks.signing.ID
+	}
+
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Parse verifies the token with the key named by its kid header
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		key, err := ks.lookup(token.Header["kid"])
+		if err != nil {
+			return nil, err
+		}
+
+		// A key only ever verifies its own algorithm
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims == nil {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+func (ks *KeySet) lookup(kid interface{}) (*Key, error) {
+	if kid == nil {
+		if ks.legacy == nil {
+			return nil, fmt.Errorf("token has no key id")
+		}
+		return ks.legacy, nil
+	}
+
+	id, ok := kid.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid key id")
+	}
+
+	key, ok := ks.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", id)
+	}
+
+	return key, nil
+}
+
+func loadKey(cfg config.JWTKey) (*Key, error) {
+	if cfg.ID == "" {
+		return nil, fmt.Errorf("key id is required")
+	}
+
+	key := &Key{ID: cfg.ID}
+
+	switch cfg.Algorithm {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		key.Method = SigningMethodEd25519
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		privateKey, err := readPrivateKey(cfg.PrivateKeyFile, cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		key.signKey = privateKey
+		key.verifyKey = privateKey.(crypto.Signer).Public()
+	}
+
+	if cfg.PublicKeyFile != "" {
+		publicKey, err := readPublicKey(cfg.PublicKeyFile, cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		key.verifyKey = publicKey
+	}
+
+	if key.verifyKey == nil {
+		return nil, fmt.Errorf("either private_key_file or public_key_file is required")
+	}
+
+	return key, nil
+}
+
+func readPrivateKey(path, algorithm string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == "RS256" {
+		return jwt.ParseRSAPrivateKeyFromPEM(data)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ed25519Key, ok := privateKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 private key", path)
+	}
+
+	return ed25519Key, nil
+}
+
+func readPublicKey(path, algorithm string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == "RS256" {
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ed25519Key, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 public key", path)
+	}
+
+	return ed25519Key, nil
+}
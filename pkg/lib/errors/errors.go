@@ -8,6 +8,27 @@ const (
 	RefreshTokenNotProvided = "Refresh token not provided"
 	InvalidRefreshToken     = "Invalid refresh token"
 	InvalidURLParameters    = "Invalid URL parameters"
+	InvalidSessionID        = "Invalid session ID"
+	SessionNotFound         = "Session not found"
+	InvalidMFACode          = "Invalid two-factor authentication code"
+	InvalidMFAToken         = "Invalid or expired two-factor authentication token"
+	MFANotEnrolled          = "Two-factor authentication is not enrolled"
+	MFAAlreadyEnabled       = "Two-factor authentication is already enabled"
+	MFARequiredForRole      = "Two-factor authentication is required for this role"
+	AccountLocked           = "Account is temporarily locked due to too many failed login attempts"
+	TooManyLoginAttempts    = "Too many failed login attempts, try again later"
+	PasswordPolicyViolation = "Password does not meet the password policy"
+	PasswordReused          = "Password has been used recently, choose a different one"
+	InvalidResetToken       = "Invalid or expired password reset token"
+	AdminDisabled           = "Admin account is disabled"
+	LocalLoginDisabled      = "Password login is disabled, sign in through single sign-on"
+	InvalidSSOState         = "Invalid or expired single sign-on request"
+	SSONoRole               = "Your identity provider groups do not grant access to the admin panel"
+	SSONotProvisioned       = "No admin account exists for this identity"
+	SSOMissingClaim         = "Identity provider did not return the required claims"
+	SSOProviderUnavailable  = "Identity provider could not complete the sign-in"
+	InvalidInvitationToken  = "Invalid, expired or revoked invitation"
+	ImpersonationNotAllowed = "Admins cannot impersonate themselves or impersonate while impersonating"
 )
 
 // user & admin
@@ -17,6 +38,41 @@ const (
 	InvalidRequestBody       = "Invalid request body"
 	InvalidPhoneNumberFormat = "Invalid phone number format"
 	SearchQueryRequired      = "Search query is required"
+	InvalidSearchQuery       = "Invalid search query"
+	InvalidEmail             = "Invalid email address"
+	AdminEmailExists         = "Admin with the same email already exists"
+	InvalidAPIKeyScope       = "Invalid API key scope"
+	APIKeyNotFound           = "API key not found"
+	APIKeyNameRequired       = "API key name is required"
+	APIKeyScopeNotHeld       = "API key scopes include permissions you do not have"
+	RoleNotFound             = "Role not found"
+	RoleAlreadyExists        = "Role with the same name already exists"
+	RoleProtected            = "Role is protected and cannot be changed or deleted"
+	RoleInUse                = "Role is still assigned to admins"
+	InvalidRoleName          = "Role name must be 2-50 lowercase letters, digits or underscores"
+	UnknownPermission        = "Unknown permission"
+	RoleOutranksCaller       = "Role holds permissions you do not have"
+	UsernameAndRoleRequired  = "Username and role are required fields"
+	InvitationNotFound       = "Invitation not found"
+	InvitationExists         = "An open invitation already exists for this username"
+	InvitationNotPending     = "Invitation has already been accepted or revoked"
+	AccessPolicyNotFound     = "Access policy not found"
+	InvalidAdminStatus       = "Status must be active or disabled"
+	DisableReasonRequired    = "A reason is required to disable an admin"
+	CannotDisableSelf        = "Admins cannot disable themselves"
+	InvalidAccessPolicy      = "Access policy must target a role or an admin and restrict networks, weekdays (0-6) or hours (0-24)"
+	AccessReviewNameRequired = "Access review name is required"
+	AccessReviewNotFound     = "Access review not found"
+	AccessReviewClosed       = "Access review is closed"
+	AccessReviewItemNotFound = "Admin is not part of this access review"
+	AccessReviewItemDecided  = "Admin has already been reviewed"
+	InvalidExportFormat      = "Export format must be csv or json"
+	UserNotFound             = "User not found"
+	InvalidUserFilter        = "Invalid user filter"
+	InvalidSort              = "Invalid sort parameter"
+	InvalidCursor            = "Invalid cursor, it may have been issued for a different sort"
+	InvalidTotalMode         = "Total must be exact or estimate"
+	UserNotDeleted           = "User is not in the trash"
 )
 
 // middleware
@@ -25,4 +81,12 @@ const (
 	RoleNotFoundInTokenClaims     = "Role not found in token claims"
 	InsufficientPermission        = "Insufficient permissions"
 	TokenClaimsNotFound           = "Token claims not found"
+	RestrictedToken               = "Token is restricted to a pending login step"
+	TokenRevoked                  = "Authorization token has been revoked"
+	InvalidAPIKey                 = "Invalid, expired or revoked API key"
+	APIKeyNotAccepted             = "API keys are not accepted for this endpoint"
+	InsufficientScope             = "API key is missing the required scope"
+	InvalidCSRFToken              = "Missing or invalid CSRF token"
+	NotAllowedWhileImpersonating  = "Not allowed while impersonating another admin"
+	AccessPolicyDenied            = "Access is not allowed from this network or at this time"
 )
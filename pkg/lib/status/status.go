@@ -10,4 +10,7 @@ const (
 	InternalServerError = http.StatusInternalServerError
 	Forbidden           = http.StatusForbidden
 	Conflict            = http.StatusConflict
+	Locked              = http.StatusLocked
+	TooManyRequests     = http.StatusTooManyRequests
+	BadGateway          = http.StatusBadGateway
 )
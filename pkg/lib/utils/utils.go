@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"log/slog"
+	"net"
 	"net/http"
 	"strings"
 	"user-admin/internal/domain"
@@ -43,6 +44,15 @@ func IsValidPhoneNumber(phoneNumber string) bool {
 	return len(phoneNumber) == 12 && strings.HasPrefix(phoneNumber, validPrefix)
 }
 
+// ClientIP returns the address of the client without the port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // Alternative for http.Error to response with json instead of plain text
 func RespondWithErrorJSON(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -65,12 +75,13 @@ func RespondWithJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func ScanUserRow(rows *sql.Rows) (domain.CommonUserResponse, error) {
+// ScanUserRow scans the user columns of a row, extra receives the columns that follow them
+func ScanUserRow(rows *sql.Rows, extra ...interface{}) (domain.CommonUserResponse, error) {
 	var user domain.CommonUserResponse
 	var firstName, lastName, gender, location, email, profilePhotoURL sql.NullString
 	var dateOfBirth sql.NullTime
 
-	if err := rows.Scan(
+	dest := []interface{}{
 		&user.ID,
 		&firstName,
 		&lastName,
@@ -81,7 +92,9 @@ func ScanUserRow(rows *sql.Rows) (domain.CommonUserResponse, error) {
 		&dateOfBirth,
 		&location,
 		&email, &profilePhotoURL,
-	); err != nil {
+	}
+
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
 		slog.Error("Error scanning user row: %v", Err(err))
 		return domain.CommonUserResponse{}, err
 	}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"user-admin/internal/config"
+)
+
+type SMTPMailer struct {
+	config config.Mail
+}
+
+func NewSMTPMailer(cfg config.Mail) *SMTPMailer {
+	return &SMTPMailer{config: cfg}
+}
+
+// Send delivers the message through the configured server, using STARTTLS when the server offers it
+func (m *SMTPMailer) Send(message Message) error {
+	msg, err := format(m.config.From, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
+	}
+
+	addr := net.JoinHostPort(m.config.SMTPHost, strconv.Itoa(m.config.SMTPPort))
+
+	return smtp.SendMail(addr, auth, m.config.From, []string{message.To}, msg)
+}
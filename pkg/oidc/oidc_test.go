@@ -0,0 +1,232 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"user-admin/internal/config"
+	"user-admin/pkg/oidc"
+	"user-admin/pkg/oidc/oidctest"
+)
+
+const (
+	testClientID    = "user-admin"
+	testSecret      = "s3cr3t:with/special chars"
+	testRedirectURL = "http://localhost:8080/api/v1/admin/auth/oidc/callback"
+)
+
+func newTestProvider(t *testing.T) *oidctest.Provider {
+	t.Helper()
+
+	provider, err := oidctest.NewProvider(testClientID, testSecret)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	t.Cleanup(provider.Close)
+
+	provider.SetClaims(map[string]interface{}{
+		"sub":                "subject-1",
+		"preferred_username": "jdoe",
+		"groups":             []string{"admins"},
+	})
+
+	return provider
+}
+
+func newTestClient(provider *oidctest.Provider, clientID string) *oidc.Client {
+	return oidc.NewClient(config.OIDC{
+		IssuerURL:    provider.Issuer(),
+		ClientID:     clientID,
+		ClientSecret: testSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "profile"},
+	})
+}
+
+type login struct {
+	state, nonce, verifier string
+}
+
+func newLogin(t *testing.T) login {
+	t.Helper()
+
+	var l login
+	for _, value := range []*string{&l.state, &l.nonce, &l.verifier} {
+		random, err := oidc.RandomString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		*value = random
+	}
+
+	return l
+}
+
+// authorize sends the browser to the provider and returns the code it redirects back with
+func authorize(t *testing.T, provider *oidctest.Provider, client *oidc.Client, l login) string {
+	t.Helper()
+
+	authorizationURL, err := client.AuthCodeURL(context.Background(), l.state, l.nonce, l.verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge") != oidc.CodeChallenge(l.verifier) || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("authorization URL does not carry the S256 challenge: %s", authorizationURL)
+	}
+	if query.Get("code_challenge") == l.verifier {
+		t.Error("authorization URL leaks the code verifier")
+	}
+
+	code, state, err := provider.Authorize(authorizationURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if state != l.state {
+		t.Fatalf("provider returned state %q, want %q", state, l.state)
+	}
+	if code == "" {
+		t.Fatal("provider returned no code")
+	}
+
+	return code
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider := newTestProvider(t)
+	client := newTestClient(provider, testClientID)
+	l := newLogin(t)
+	ctx := context.Background()
+
+	code := authorize(t, provider, client, l)
+
+	token, err := client.Exchange(ctx, code, l.verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := client.VerifyIDToken(ctx, token.IDToken, l.nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims["sub"] != "subject-1" || claims["preferred_username"] != "jdoe" {
+		t.Errorf("unexpected claims %v", claims)
+	}
+	if claims["iss"] != provider.Issuer() {
+		t.Errorf("iss = %v, want %s", claims["iss"], provider.Issuer())
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	provider := newTestProvider(t)
+	client := newTestClient(provider, testClientID)
+	l := newLogin(t)
+
+	code := authorize(t, provider, client, l)
+
+	if _, err := client.Exchange(context.Background(), code, newLogin(t).verifier); err == nil {
+		t.Error("Exchange accepted a code verifier that does not match the challenge")
+	}
+}
+
+func TestExchangeRejectsReusedCode(t *testing.T) {
+	provider := newTestProvider(t)
+	client := newTestClient(provider, testClientID)
+	l := newLogin(t)
+	ctx := context.Background()
+
+	code := authorize(t, provider, client, l)
+
+	if _, err := client.Exchange(ctx, code, l.verifier); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := client.Exchange(ctx, code, l.verifier); err == nil {
+		t.Error("Exchange accepted an authorization code twice")
+	}
+}
+
+func TestVerifyIDTokenRejectsWrongNonce(t *testing.T) {
+	provider := newTestProvider(t)
+	client := newTestClient(provider, testClientID)
+	l := newLogin(t)
+	ctx := context.Background()
+
+	token, err := client.Exchange(ctx, authorize(t, provider, client, l), l.verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if _, err := client.VerifyIDToken(ctx, token.IDToken, newLogin(t).nonce); err == nil {
+		t.Error("VerifyIDToken accepted a token with another nonce")
+	}
+}
+
+func TestVerifyIDTokenRejectsOtherAudience(t *testing.T) {
+	provider := newTestProvider(t)
+	client := newTestClient(provider, testClientID)
+	l := newLogin(t)
+	ctx := context.Background()
+
+	token, err := client.Exchange(ctx, authorize(t, provider, client, l), l.verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	other := newTestClient(provider, "another-client")
+	if _, err := other.VerifyIDToken(ctx, token.IDToken, l.nonce); err == nil {
+		t.Error("VerifyIDToken accepted a token issued to another client")
+	}
+}
+
+func TestVerifyIDTokenRejectsTamperedToken(t *testing.T) {
+	provider := newTestProvider(t)
+	client := newTestClient(provider, testClientID)
+	l := newLogin(t)
+	ctx := context.Background()
+
+	token, err := client.Exchange(ctx, authorize(t, provider, client, l), l.verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	// Change a character inside the signature, the last one only carries padding bits
+	tampered := []byte(token.IDToken)
+	i := len(tampered) - 10
+	if tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+
+	if _, err := client.VerifyIDToken(ctx, string(tampered), l.nonce); err == nil {
+		t.Error("VerifyIDToken accepted a token with a broken signature")
+	}
+}
+
+func TestProviderIssuerMustMatch(t *testing.T) {
+	provider := newTestProvider(t)
+	client := oidc.NewClient(config.OIDC{
+		IssuerURL:   provider.Issuer() + "/",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	})
+
+	// A trailing slash on the configured issuer is tolerated
+	if _, err := client.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err != nil {
+		t.Errorf("AuthCodeURL with trailing slash issuer: %v", err)
+	}
+
+	mismatched := oidc.NewClient(config.OIDC{
+		IssuerURL:   provider.Issuer() + "/tenant",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	})
+	if _, err := mismatched.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Error("AuthCodeURL accepted a provider reporting another issuer")
+	}
+}
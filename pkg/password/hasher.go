@@ -0,0 +1,176 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"user-admin/internal/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// Hasher hashes new passwords with the configured algorithm. It verifies hashes of every
+// supported algorithm, argon2id in PHC string format and bcrypt, so the algorithm can be
+// switched without invalidating existing passwords.
+type Hasher struct {
+	algorithm string
+	argon2    argon2Params
+	bcrypt    int
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+func NewHasher(cfg config.PasswordHashing) (*Hasher, error) {
+	hasher := &Hasher{
+		algorithm: cfg.HashAlgorithm,
+		argon2: argon2Params{
+			memory:      cfg.Argon2Memory,
+			iterations:  cfg.Argon2Iterations,
+			parallelism: cfg.Argon2Parallelism,
+			saltLength:  cfg.Argon2SaltLength,
+			keyLength:   cfg.Argon2KeyLength,
+		},
+		bcrypt: cfg.BcryptCost,
+	}
+
+	switch hasher.algorithm {
+	case AlgorithmArgon2id:
+		if hasher.argon2.memory < 8*uint32(hasher.argon2.parallelism) || hasher.argon2.iterations < 1 ||
+			hasher.argon2.parallelism < 1 || hasher.argon2.saltLength < 8 || hasher.argon2.keyLength < 16 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+	case AlgorithmBcrypt:
+		if hasher.bcrypt < bcrypt.MinCost || hasher.bcrypt > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", hasher.algorithm)
+	}
+
+	return hasher, nil
+}
+
+// Hash returns the hash of the password with the configured algorithm and parameters
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.bcrypt)
+		if err != nil {
+			return "", err
+		}
+
+		return string(hashed), nil
+	}
+
+	salt := make([]byte, h.argon2.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.argon2.iterations, h.argon2.memory, h.argon2.parallelism, h.argon2.keyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.argon2.memory, h.argon2.iterations, h.argon2.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether the password matches the hash, whichever supported algorithm made it
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+
+		candidate := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, params.keyLength)
+
+		return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// NeedsRehash reports whether the hash was made with another algorithm or weaker parameters
+// than the configured ones
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if h.algorithm != AlgorithmArgon2id {
+			return true
+		}
+
+		params, _, _, err := decodeArgon2id(hash)
+		if err != nil {
+			return true
+		}
+
+		return params.memory != h.argon2.memory ||
+			params.iterations != h.argon2.iterations ||
+			params.parallelism != h.argon2.parallelism ||
+			params.keyLength != h.argon2.keyLength ||
+			params.saltLength != h.argon2.saltLength
+	}
+
+	if h.algorithm != AlgorithmBcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.bcrypt
+}
+
+// decodeArgon2id parses a hash in PHC string format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %v", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %v", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id key: %v", err)
+	}
+
+	params.saltLength = uint32(len(salt))
+	params.keyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,180 @@
+package password
+
+import (
+	"strings"
+	"testing"
+	"user-admin/internal/config"
+)
+
+// Cheap parameters keep the tests fast, they are not meant for production
+func testConfig(algorithm string) config.PasswordHashing {
+	return config.PasswordHashing{
+		HashAlgorithm:     algorithm,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+		BcryptCost:        4,
+	}
+}
+
+func newTestHasher(t *testing.T, cfg config.PasswordHashing) *Hasher {
+	t.Helper()
+
+	hasher, err := NewHasher(cfg)
+	if err != nil {
+		t.Fatalf("NewHasher: %v", err)
+	}
+
+	return hasher
+}
+
+func TestHashVerifyRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmArgon2id, AlgorithmBcrypt} {
+		t.Run(algorithm, func(t *testing.T) {
+			hasher := newTestHasher(t, testConfig(algorithm))
+
+			hash, err := hasher.Hash("correct horse battery staple")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			if algorithm == AlgorithmArgon2id && !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+				t.Errorf("unexpected argon2id hash format %q", hash)
+			}
+
+			ok, err := hasher.Verify(hash, "correct horse battery staple")
+			if err != nil || !ok {
+				t.Errorf("Verify(correct password) = (%v, %v), want (true, nil)", ok, err)
+			}
+
+			ok, err = hasher.Verify(hash, "correct horse battery stapler")
+			if err != nil || ok {
+				t.Errorf("Verify(wrong password) = (%v, %v), want (false, nil)", ok, err)
+			}
+
+			if hasher.NeedsRehash(hash) {
+				t.Error("NeedsRehash reported a fresh hash")
+			}
+		})
+	}
+}
+
+func TestHashUsesRandomSalt(t *testing.T) {
+	hasher := newTestHasher(t, testConfig(AlgorithmArgon2id))
+
+	first, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("two hashes of the same password are equal")
+	}
+}
+
+func TestVerifyAcrossAlgorithms(t *testing.T) {
+	bcryptHasher := newTestHasher(t, testConfig(AlgorithmBcrypt))
+	argon2Hasher := newTestHasher(t, testConfig(AlgorithmArgon2id))
+
+	hash, err := bcryptHasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := argon2Hasher.Verify(hash, "secret")
+	if err != nil || !ok {
+		t.Errorf("argon2id hasher did not verify a bcrypt hash: (%v, %v)", ok, err)
+	}
+	if !argon2Hasher.NeedsRehash(hash) {
+		t.Error("argon2id hasher did not ask to rehash a bcrypt hash")
+	}
+
+	hash, err = argon2Hasher.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bcryptHasher.NeedsRehash(hash) {
+		t.Error("bcrypt hasher did not ask to rehash an argon2id hash")
+	}
+}
+
+func TestNeedsRehashOnChangedParameters(t *testing.T) {
+	old := newTestHasher(t, testConfig(AlgorithmArgon2id))
+	hash, err := old.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig(AlgorithmArgon2id)
+	cfg.Argon2Iterations = 2
+	stronger := newTestHasher(t, cfg)
+
+	if !stronger.NeedsRehash(hash) {
+		t.Error("NeedsRehash ignored changed argon2id iterations")
+	}
+
+	ok, err := stronger.Verify(hash, "secret")
+	if err != nil || !ok {
+		t.Errorf("hash made with old parameters no longer verifies: (%v, %v)", ok, err)
+	}
+
+	rehashed, err := stronger.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stronger.NeedsRehash(rehashed) {
+		t.Error("NeedsRehash reported a hash made with the current parameters")
+	}
+
+	bcryptOld := newTestHasher(t, testConfig(AlgorithmBcrypt))
+	hash, err = bcryptOld.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg = testConfig(AlgorithmBcrypt)
+	cfg.BcryptCost = 5
+	if !newTestHasher(t, cfg).NeedsRehash(hash) {
+		t.Error("NeedsRehash ignored a changed bcrypt cost")
+	}
+}
+
+func TestVerifyRejectsMalformedArgon2idHash(t *testing.T) {
+	hasher := newTestHasher(t, testConfig(AlgorithmArgon2id))
+
+	for _, hash := range []string{
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5a2V5",
+	} {
+		if ok, err := hasher.Verify(hash, "secret"); err == nil || ok {
+			t.Errorf("Verify(%q) = (%v, %v), want an error", hash, ok, err)
+		}
+		if !hasher.NeedsRehash(hash) {
+			t.Errorf("NeedsRehash(%q) = false for a malformed hash", hash)
+		}
+	}
+}
+
+func TestNewHasherRejectsInvalidConfig(t *testing.T) {
+	cfg := testConfig("md5")
+	if _, err := NewHasher(cfg); err == nil {
+		t.Error("NewHasher accepted an unsupported algorithm")
+	}
+
+	cfg = testConfig(AlgorithmArgon2id)
+	cfg.Argon2SaltLength = 4
+	if _, err := NewHasher(cfg); err == nil {
+		t.Error("NewHasher accepted a 4 byte argon2id salt")
+	}
+
+	cfg = testConfig(AlgorithmBcrypt)
+	cfg.BcryptCost = 3
+	if _, err := NewHasher(cfg); err == nil {
+		t.Error("NewHasher accepted a bcrypt cost below the minimum")
+	}
+}
@@ -0,0 +1,116 @@
+// Package password enforces the password policy for admin accounts and hashes their passwords
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"user-admin/internal/config"
+)
+
+// PolicyError lists every rule a password broke, so that they can be shown at once
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Violations, "; ")
+}
+
+type Policy struct {
+	config    config.PasswordPolicy
+	blocklist map[string]struct{}
+}
+
+// NewPolicy builds the policy and loads the blocklist of breached or common passwords, one per line
+func NewPolicy(cfg config.PasswordPolicy) (*Policy, error) {
+	policy := &Policy{config: cfg, blocklist: make(map[string]struct{})}
+
+	if cfg.BlocklistFile == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(cfg.BlocklistFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening password blocklist: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if entry != "" {
+			policy.blocklist[entry] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading password blocklist: %v", err)
+	}
+
+	return policy, nil
+}
+
+// Validate checks the password against every rule of the policy
+func (p *Policy) Validate(password, username string) error {
+	var violations []string
+
+	if len([]rune(password)) < p.config.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", p.config.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.config.RequireUpper && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.config.RequireLower && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if username != "" && strings.Contains(lowered, strings.ToLower(username)) {
+		violations = append(violations, "must not contain the username")
+	}
+
+	if _, blocked := p.blocklist[lowered]; blocked {
+		violations = append(violations, "is too common or has appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// HistorySize is the number of previous passwords that may not be reused
+func (p *Policy) HistorySize() int {
+	return p.config.HistorySize
+}
+
+// IsExpired reports whether a password set at changedAt has to be changed, zero MaxAge never expires
+func (p *Policy) IsExpired(changedAt time.Time) bool {
+	return p.config.MaxAge > 0 && time.Since(changedAt) > p.config.MaxAge
+}